@@ -18,8 +18,8 @@ func main() {
 	}
 	defer app.Close()
 
-	// Otherwise, start the server normally
-	if err := app.Start(); err != nil {
-		logging.LogError("Failed to start server: %v", err)
+	// Serve until interrupted, then shut down gracefully
+	if err := app.Run(); err != nil {
+		logging.LogError("Server did not shut down cleanly: %v", err)
 	}
 }
\ No newline at end of file
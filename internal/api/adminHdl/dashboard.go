@@ -3,7 +3,10 @@ package adminHdl
 import (
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
 	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
 	"github.com/labstack/echo/v4"
@@ -279,6 +282,109 @@ func (h *DashboardHandler) GetPricingAnalytics(c echo.Context) error {
 	})
 }
 
+// BacktestRequest selects the model and the date range (YYYY-MM-DD) to replay.
+// Either model_version or coefficients may be given; with neither the active model is used.
+type BacktestRequest struct {
+	ModelVersion string                       `json:"model_version"`
+	Coefficients *repo.PriceModelCoefficients `json:"coefficients"`
+	From         string                       `json:"from"`
+	To           string                       `json:"to"`
+	ProductIds   []int                        `json:"product_ids"`
+}
+
+// RunBacktest - What-if simulation of a model over historical sales
+func (h *DashboardHandler) RunBacktest(c echo.Context) error {
+	logging.LogInfo("RunBacktest: init")
+	var req BacktestRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Error:   "Invalid request payload",
+		})
+	}
+
+	// Default to the last 30 days
+	to := time.Now().UTC()
+	if req.To != "" {
+		parsed, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, DashboardResponse{Success: false, Error: "Invalid to date, expected YYYY-MM-DD"})
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -30)
+	if req.From != "" {
+		parsed, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, DashboardResponse{Success: false, Error: "Invalid from date, expected YYYY-MM-DD"})
+		}
+		from = parsed
+	}
+
+	data, err := h.dashboardService.RunBacktest(c.Request().Context(), req.ModelVersion, req.Coefficients, from, to, req.ProductIds)
+	if err != nil {
+		logging.LogError("RunBacktest: Failed to run backtest: " + err.Error())
+		return c.JSON(http.StatusBadRequest, DashboardResponse{
+			Success: false,
+			Error:   "Failed to run backtest: " + err.Error(),
+		})
+	}
+
+	logging.LogInfo("RunBacktest: success")
+	return c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Backtest completed successfully",
+		Data:    data,
+	})
+}
+
+// GetElasticities - Price elasticity per product
+func (h *DashboardHandler) GetElasticities(c echo.Context) error {
+	logging.LogInfo("GetElasticities: init")
+	data, err := h.dashboardService.GetElasticities()
+	if err != nil {
+		logging.LogError("GetElasticities: Failed to fetch elasticities: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Error:   "Failed to fetch elasticities: " + err.Error(),
+		})
+	}
+
+	logging.LogInfo("GetElasticities: success")
+	return c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Elasticities retrieved successfully",
+		Data:    data,
+	})
+}
+
+// EstimateElasticities - Recompute elasticities over ?days of history
+func (h *DashboardHandler) EstimateElasticities(c echo.Context) error {
+	logging.LogInfo("EstimateElasticities: init")
+	days := pricing.DefaultElasticityWindowDays
+	if daysParam := c.QueryParam("days"); daysParam != "" {
+		if parsedDays, err := strconv.Atoi(daysParam); err == nil && parsedDays > 0 {
+			days = parsedDays
+		}
+	}
+
+	data, err := h.dashboardService.EstimateElasticities(c.Request().Context(), days)
+	if err != nil {
+		logging.LogError("EstimateElasticities: Failed to estimate elasticities: " + err.Error())
+		return c.JSON(http.StatusInternalServerError, DashboardResponse{
+			Success: false,
+			Error:   "Failed to estimate elasticities: " + err.Error(),
+		})
+	}
+
+	logging.LogInfo("EstimateElasticities: success")
+	return c.JSON(http.StatusOK, DashboardResponse{
+		Success: true,
+		Message: "Elasticities estimated successfully",
+		Data:    data,
+	})
+}
+
 // GetCustomerBehavior - Specific customer behavior endpoint
 func (h *DashboardHandler) GetCustomerBehavior(c echo.Context) error {
 	logging.LogInfo("GetCustomerBehavior: init")
@@ -0,0 +1,27 @@
+package adminHdl
+
+import (
+	"net/http"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/events"
+	"github.com/labstack/echo/v4"
+)
+
+type EventHandler struct {
+	hub *events.Hub
+}
+
+func NewEventHandler(hub *events.Hub) *EventHandler {
+	return &EventHandler{hub: hub}
+}
+
+// Stream handles streaming live events of every topic, including low-stock alerts and all
+// customers' order statuses, filtered by ?topics, ?product_ids and ?category_ids
+func (h *EventHandler) Stream(c echo.Context) error {
+	return events.ServeSSE(c, h.hub, events.AudienceAdmin, 0)
+}
+
+// GetStats handles retrieving the live event clients, dropped events and queue depths
+func (h *EventHandler) GetStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.hub.Stats())
+}
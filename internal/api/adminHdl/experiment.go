@@ -0,0 +1,112 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+type PriceExperimentHandler struct {
+	experimentService *adminSvc.PriceExperimentService
+}
+
+func NewPriceExperimentHandler(experimentService *adminSvc.PriceExperimentService) *PriceExperimentHandler {
+	return &PriceExperimentHandler{experimentService: experimentService}
+}
+
+// experimentError maps an experiment error to a response
+func experimentError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, pricing.ErrExperimentNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, pricing.ErrExperimentStopped):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// CreateExperiment handles starting a new price experiment
+func (h *PriceExperimentHandler) CreateExperiment(c echo.Context) error {
+	var experiment repo.PriceExperiment
+	if err := c.Bind(&experiment); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+	if username, ok := c.Get("username").(string); ok {
+		experiment.CreatedBy = &username
+	}
+
+	createdExperiment, err := h.experimentService.CreateExperiment(c.Request().Context(), &experiment)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, createdExperiment)
+}
+
+// GetExperiments handles listing experiments, filtered by ?status (running or stopped)
+func (h *PriceExperimentHandler) GetExperiments(c echo.Context) error {
+	status := repo.ExperimentStatus(c.QueryParam("status"))
+	switch status {
+	case "", repo.ExperimentStatusRunning, repo.ExperimentStatusStopped:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
+
+	experiments, err := h.experimentService.GetExperiments(c.Request().Context(), status)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, experiments)
+}
+
+// GetExperimentByID handles retrieving an experiment by its ID
+func (h *PriceExperimentHandler) GetExperimentByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid experiment ID"})
+	}
+
+	experiment, err := h.experimentService.GetExperimentByID(c.Request().Context(), id)
+	if err != nil {
+		return experimentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, experiment)
+}
+
+// StopExperiment handles stopping a running experiment
+func (h *PriceExperimentHandler) StopExperiment(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid experiment ID"})
+	}
+
+	experiment, err := h.experimentService.StopExperiment(c.Request().Context(), id)
+	if err != nil {
+		return experimentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, experiment)
+}
+
+// GetExperimentReport handles reporting conversion and revenue per arm
+func (h *PriceExperimentHandler) GetExperimentReport(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid experiment ID"})
+	}
+
+	report, err := h.experimentService.GetExperimentReport(c.Request().Context(), id)
+	if err != nil {
+		return experimentError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
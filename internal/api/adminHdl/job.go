@@ -0,0 +1,153 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/scheduler"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultJobRunsLimit caps how many runs are listed when no limit is given
+const defaultJobRunsLimit = 50
+
+type JobHandler struct {
+	jobService *adminSvc.JobService
+}
+
+func NewJobHandler(jobService *adminSvc.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// jobError maps a job error to a response
+func jobError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, scheduler.ErrJobNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, scheduler.ErrInvalidCron):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, scheduler.ErrRunAlreadyQueued):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// currentAdmin is the admin making a request
+func currentAdmin(c echo.Context) *string {
+	if username, ok := c.Get("username").(string); ok {
+		return &username
+	}
+	return nil
+}
+
+// GetJobs handles listing the background jobs
+func (h *JobHandler) GetJobs(c echo.Context) error {
+	jobs, err := h.jobService.GetJobs(c.Request().Context())
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// GetJobByID handles retrieving a job by its ID
+func (h *JobHandler) GetJobByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	job, err := h.jobService.GetJobByID(c.Request().Context(), id)
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// GetRuns handles listing a job's runs with their outcome and errors, capped by ?limit
+func (h *JobHandler) GetRuns(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	limit := defaultJobRunsLimit
+	if param := c.QueryParam("limit"); param != "" {
+		l, err := strconv.Atoi(param)
+		if err != nil || l <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = l
+	}
+
+	runs, err := h.jobService.GetRuns(c.Request().Context(), id, limit)
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, runs)
+}
+
+// TriggerJob handles queueing a run of a job now
+func (h *JobHandler) TriggerJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	run, err := h.jobService.TriggerJob(c.Request().Context(), id, currentAdmin(c))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, run)
+}
+
+// PauseJob handles stopping a job's scheduled runs
+func (h *JobHandler) PauseJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	job, err := h.jobService.PauseJob(c.Request().Context(), id, currentAdmin(c))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// ResumeJob handles restarting a paused job's scheduled runs
+func (h *JobHandler) ResumeJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	job, err := h.jobService.ResumeJob(c.Request().Context(), id, currentAdmin(c))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// UpdateSchedule handles changing a job's cron_expression, a five-field expression in UTC
+// or a descriptor such as @daily
+func (h *JobHandler) UpdateSchedule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+	var req struct {
+		CronExpression string `json:"cron_expression"`
+	}
+	if err := c.Bind(&req); err != nil || req.CronExpression == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "cron_expression is required"})
+	}
+
+	job, err := h.jobService.UpdateSchedule(c.Request().Context(), id, req.CronExpression, currentAdmin(c))
+	if err != nil {
+		return jobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
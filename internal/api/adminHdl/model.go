@@ -0,0 +1,166 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultShadowPredictionsLimit caps how many shadow predictions are returned when no limit is given
+const defaultShadowPredictionsLimit = 100
+
+type ModelRegistryHandler struct {
+	modelService *adminSvc.ModelRegistryService
+}
+
+func NewModelRegistryHandler(modelService *adminSvc.ModelRegistryService) *ModelRegistryHandler {
+	return &ModelRegistryHandler{modelService: modelService}
+}
+
+// modelError maps a registry error to a response
+func modelError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, pricing.ErrModelNotFound), errors.Is(err, pricing.ErrNoPricingData):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, pricing.ErrModelIsActive), errors.Is(err, pricing.ErrNoActiveModel), errors.Is(err, pricing.ErrNoPreviousModel):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// GetModels handles listing every registered model
+func (h *ModelRegistryHandler) GetModels(c echo.Context) error {
+	models, err := h.modelService.GetModels(c.Request().Context())
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, models)
+}
+
+// GetModel handles retrieving a model by its version
+func (h *ModelRegistryHandler) GetModel(c echo.Context) error {
+	model, err := h.modelService.GetModel(c.Request().Context(), c.Param("version"))
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, model)
+}
+
+// PromoteModel handles making a model the active one
+func (h *ModelRegistryHandler) PromoteModel(c echo.Context) error {
+	model, err := h.modelService.PromoteModel(c.Request().Context(), c.Param("version"))
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, model)
+}
+
+// RollbackModel handles reactivating the previously active model
+func (h *ModelRegistryHandler) RollbackModel(c echo.Context) error {
+	model, err := h.modelService.RollbackModel(c.Request().Context())
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, model)
+}
+
+// ShadowModel handles putting a model in shadow mode
+func (h *ModelRegistryHandler) ShadowModel(c echo.Context) error {
+	model, err := h.modelService.ShadowModel(c.Request().Context(), c.Param("version"))
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, model)
+}
+
+// RetireModel handles taking a candidate or shadow model out of use
+func (h *ModelRegistryHandler) RetireModel(c echo.Context) error {
+	model, err := h.modelService.RetireModel(c.Request().Context(), c.Param("version"))
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, model)
+}
+
+// TrainModel handles training a candidate model on the snapshots in a window.
+// from and to are YYYY-MM-DD dates; to is inclusive and defaults to today, from defaults to
+// pricing.DefaultTrainingWindowDays days earlier.
+func (h *ModelRegistryHandler) TrainModel(c echo.Context) error {
+	var req struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.To != "" {
+		parsed, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid to date, expected YYYY-MM-DD"})
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -pricing.DefaultTrainingWindowDays)
+	if req.From != "" {
+		parsed, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid from date, expected YYYY-MM-DD"})
+		}
+		from = parsed
+	}
+
+	model, err := h.modelService.TrainModel(c.Request().Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, model)
+}
+
+// GetTrainingSet handles retrieving the feature snapshots a model was trained on
+func (h *ModelRegistryHandler) GetTrainingSet(c echo.Context) error {
+	snapshots, err := h.modelService.GetTrainingSet(c.Request().Context(), c.Param("version"))
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, snapshots)
+}
+
+// GetShadowReport handles retrieving the logged price differences for a shadow model, capped by ?limit
+func (h *ModelRegistryHandler) GetShadowReport(c echo.Context) error {
+	limit := defaultShadowPredictionsLimit
+	if param := c.QueryParam("limit"); param != "" {
+		l, err := strconv.Atoi(param)
+		if err != nil || l <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = l
+	}
+
+	report, err := h.modelService.GetShadowReport(c.Request().Context(), c.Param("version"), limit)
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// ExplainPrice handles breaking a product's price down into per-feature contributions
+func (h *ModelRegistryHandler) ExplainPrice(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+	}
+
+	explanation, err := h.modelService.ExplainPrice(c.Request().Context(), id)
+	if err != nil {
+		return modelError(c, err)
+	}
+	return c.JSON(http.StatusOK, explanation)
+}
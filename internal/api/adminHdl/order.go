@@ -0,0 +1,123 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultOrderPageSize = 50
+	maxOrderPageSize     = 200
+)
+
+type OrderHandler struct {
+	orderService *adminSvc.OrderService
+}
+
+func NewOrderHandler(orderService *adminSvc.OrderService) *OrderHandler {
+	return &OrderHandler{orderService: orderService}
+}
+
+// orderError maps an order error to a response
+func orderError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, orders.ErrOrderNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, adminSvc.ErrNotFulfilmentStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, orders.ErrInvalidTransition):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// parseOrderFilter reads the shared order list parameters and ?customer_id
+func parseOrderFilter(c echo.Context) (repo.OrderFilter, error) {
+	filter, err := orders.ParseFilter(c.QueryParams(), defaultOrderPageSize, maxOrderPageSize)
+	if err != nil {
+		return filter, err
+	}
+
+	if param := c.QueryParam("customer_id"); param != "" {
+		customerId, err := strconv.Atoi(param)
+		if err != nil {
+			return filter, errors.New("invalid customer ID")
+		}
+		filter.CustomerId = customerId
+	}
+	return filter, nil
+}
+
+// GetOrders handles listing every customer's orders, newest first, filtered by ?customer_id,
+// ?status, ?from and ?to and paged by ?page and ?page_size
+func (h *OrderHandler) GetOrders(c echo.Context) error {
+	filter, err := parseOrderFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	page, err := h.orderService.GetOrders(c.Request().Context(), filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetOrderByID handles retrieving an order with its items, payments and status history
+func (h *OrderHandler) GetOrderByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+	}
+
+	order, err := h.orderService.GetOrderByID(c.Request().Context(), id)
+	if err != nil {
+		return orderError(c, err)
+	}
+	return c.JSON(http.StatusOK, order)
+}
+
+// GetOrderHistory handles listing an order's status changes, oldest first
+func (h *OrderHandler) GetOrderHistory(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+	}
+
+	history, err := h.orderService.GetOrderHistory(c.Request().Context(), id)
+	if err != nil {
+		return orderError(c, err)
+	}
+	return c.JSON(http.StatusOK, history)
+}
+
+// UpdateOrderStatus handles moving an order along fulfilment, or refunding it, with a body of
+// {"status": ..., "reason": ...}
+func (h *OrderHandler) UpdateOrderStatus(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+	}
+	var req struct {
+		Status repo.OrderStatus `json:"status"`
+		Reason string           `json:"reason"`
+	}
+	if err := c.Bind(&req); err != nil || !orders.IsStatus(req.Status) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "a valid status is required"})
+	}
+
+	username, _ := c.Get("username").(string)
+	order, err := h.orderService.UpdateOrderStatus(c.Request().Context(), id, req.Status, username, req.Reason)
+	if err != nil {
+		return orderError(c, err)
+	}
+	return c.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,140 @@
+package adminHdl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultRejectionsLimit caps how many rejections are returned when no limit is given
+const defaultRejectionsLimit = 100
+
+type PricingPolicyHandler struct {
+	policyService *adminSvc.PricingPolicyService
+}
+
+func NewPricingPolicyHandler(policyService *adminSvc.PricingPolicyService) *PricingPolicyHandler {
+	return &PricingPolicyHandler{policyService: policyService}
+}
+
+// CreatePolicy handles the creation of a new pricing policy
+func (h *PricingPolicyHandler) CreatePolicy(c echo.Context) error {
+	policy := repo.PricingPolicy{IsActive: true}
+	if err := c.Bind(&policy); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+
+	createdPolicy, err := h.policyService.CreatePolicy(c.Request().Context(), &policy)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, createdPolicy)
+}
+
+// GetPolicyByID handles retrieving a pricing policy by its ID
+func (h *PricingPolicyHandler) GetPolicyByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid policy ID"})
+	}
+
+	policy, err := h.policyService.GetPolicyByID(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, policy)
+}
+
+// GetAllPolicies handles retrieving all pricing policies
+func (h *PricingPolicyHandler) GetAllPolicies(c echo.Context) error {
+	policies, err := h.policyService.GetAllPolicies(c.Request().Context())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, policies)
+}
+
+// UpdatePolicy handles updating an existing pricing policy. Policies stay active unless the
+// body sets is_active to false.
+func (h *PricingPolicyHandler) UpdatePolicy(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid policy ID"})
+	}
+
+	policy := repo.PricingPolicy{IsActive: true}
+	if err := c.Bind(&policy); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+	policy.Id = id
+
+	updatedPolicy, err := h.policyService.UpdatePolicy(c.Request().Context(), &policy)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, updatedPolicy)
+}
+
+// DeletePolicy handles deleting a pricing policy
+func (h *PricingPolicyHandler) DeletePolicy(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid policy ID"})
+	}
+
+	if err := h.policyService.DeletePolicy(c.Request().Context(), id); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "pricing policy deleted successfully"})
+}
+
+// GetEffectivePolicy handles retrieving the merged policy limits for a product
+func (h *PricingPolicyHandler) GetEffectivePolicy(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+	}
+
+	policy, err := h.policyService.GetEffectivePolicy(c.Request().Context(), id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, policy)
+}
+
+// GetRejections handles retrieving rejected price changes, filtered by ?product_id and capped by ?limit
+func (h *PricingPolicyHandler) GetRejections(c echo.Context) error {
+	productId := 0
+	if param := c.QueryParam("product_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		}
+		productId = id
+	}
+
+	limit := defaultRejectionsLimit
+	if param := c.QueryParam("limit"); param != "" {
+		l, err := strconv.Atoi(param)
+		if err != nil || l <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = l
+	}
+
+	rejections, err := h.policyService.GetRejections(c.Request().Context(), productId, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, rejections)
+}
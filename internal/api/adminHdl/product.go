@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
 	"github.com/Daniel-Njaramba-1/pulse/internal/util/imageHdl"
@@ -239,12 +240,46 @@ func (h *ProductHandler) UpdateProductPrice(c echo.Context) error {
 	// Call service method
 	err = h.productService.ChangeBasePrice(c.Request().Context(), id, req.BasePrice)
 	if err != nil {
+		var violation *pricing.PolicyViolation
+		if errors.As(err, &violation) {
+			return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Price rejected by pricing policy", "details": violation.Reason})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update product price", "details": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"message": "Product price updated successfully"})
 }
 
+func (h *ProductHandler) UpdateProductCost(c echo.Context) error {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid product ID"})
+	}
+
+	// Parse request body
+	type CostRequest struct {
+		CostPrice float64 `json:"cost_price"`
+	}
+
+	var req CostRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	err = h.productService.ChangeCostPrice(c.Request().Context(), id, req.CostPrice)
+	switch {
+	case errors.Is(err, adminSvc.ErrNegativeCost):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, adminSvc.ErrProductNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update product cost", "details": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Product cost updated successfully"})
+}
+
 func (h *ProductHandler) UpdateProductStock(c echo.Context) error {
 	logging.LogInfo("UpdateProductStock called")
 	idStr := c.Param("id")
@@ -0,0 +1,159 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultProposalsLimit caps how many proposals are returned when no limit is given
+const defaultProposalsLimit = 100
+
+type PricingProposalHandler struct {
+	proposalService *adminSvc.PricingProposalService
+}
+
+func NewPricingProposalHandler(proposalService *adminSvc.PricingProposalService) *PricingProposalHandler {
+	return &PricingProposalHandler{proposalService: proposalService}
+}
+
+type reviewRequest struct {
+	Ids  []int  `json:"ids"`
+	Note string `json:"note"`
+}
+
+// proposalError maps a review error to a response
+func proposalError(c echo.Context, proposal *repo.PriceProposal, err error) error {
+	var violation *pricing.PolicyViolation
+	switch {
+	case errors.As(err, &violation):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{"error": "Proposal rejected by pricing policy", "details": violation.Reason, "proposal": proposal})
+	case errors.Is(err, pricing.ErrProposalNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, pricing.ErrProposalNotPending), errors.Is(err, pricing.ErrProposalHeld):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// GetProposals handles listing proposals, filtered by ?status (default pending), ?product_id and ?limit
+func (h *PricingProposalHandler) GetProposals(c echo.Context) error {
+	status := repo.ProposalStatus(c.QueryParam("status"))
+	switch status {
+	case "":
+		status = repo.ProposalStatusPending
+	case "all":
+		status = ""
+	case repo.ProposalStatusPending, repo.ProposalStatusApproved, repo.ProposalStatusAutoApproved,
+		repo.ProposalStatusRejected, repo.ProposalStatusSuperseded:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
+
+	productId := 0
+	if param := c.QueryParam("product_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		}
+		productId = id
+	}
+
+	limit := defaultProposalsLimit
+	if param := c.QueryParam("limit"); param != "" {
+		l, err := strconv.Atoi(param)
+		if err != nil || l <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = l
+	}
+
+	proposals, err := h.proposalService.GetProposals(c.Request().Context(), status, productId, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, proposals)
+}
+
+// GetProposalByID handles retrieving a proposal by its ID
+func (h *PricingProposalHandler) GetProposalByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid proposal ID"})
+	}
+
+	proposal, err := h.proposalService.GetProposalByID(c.Request().Context(), id)
+	if err != nil {
+		return proposalError(c, nil, err)
+	}
+
+	return c.JSON(http.StatusOK, proposal)
+}
+
+// ApproveProposal handles approving a single proposal
+func (h *PricingProposalHandler) ApproveProposal(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid proposal ID"})
+	}
+	reviewer, _ := c.Get("username").(string)
+
+	proposal, err := h.proposalService.ApproveProposal(c.Request().Context(), id, reviewer)
+	if err != nil {
+		return proposalError(c, proposal, err)
+	}
+
+	return c.JSON(http.StatusOK, proposal)
+}
+
+// RejectProposal handles rejecting a single proposal with an optional note
+func (h *PricingProposalHandler) RejectProposal(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid proposal ID"})
+	}
+	reviewer, _ := c.Get("username").(string)
+
+	var req reviewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+
+	proposal, err := h.proposalService.RejectProposal(c.Request().Context(), id, reviewer, req.Note)
+	if err != nil {
+		return proposalError(c, proposal, err)
+	}
+
+	return c.JSON(http.StatusOK, proposal)
+}
+
+// ApproveProposals handles approving several proposals at once
+func (h *PricingProposalHandler) ApproveProposals(c echo.Context) error {
+	var req reviewRequest
+	if err := c.Bind(&req); err != nil || len(req.Ids) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input, ids are required"})
+	}
+	reviewer, _ := c.Get("username").(string)
+
+	results := h.proposalService.ApproveProposals(c.Request().Context(), req.Ids, reviewer)
+	return c.JSON(http.StatusOK, results)
+}
+
+// RejectProposals handles rejecting several proposals at once
+func (h *PricingProposalHandler) RejectProposals(c echo.Context) error {
+	var req reviewRequest
+	if err := c.Bind(&req); err != nil || len(req.Ids) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input, ids are required"})
+	}
+	reviewer, _ := c.Get("username").(string)
+
+	results := h.proposalService.RejectProposals(c.Request().Context(), req.Ids, reviewer, req.Note)
+	return c.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,99 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultRepricingJobsLimit caps how many jobs are listed when no limit is given
+const defaultRepricingJobsLimit = 100
+
+type RepricingJobHandler struct {
+	jobService *adminSvc.RepricingJobService
+}
+
+func NewRepricingJobHandler(jobService *adminSvc.RepricingJobService) *RepricingJobHandler {
+	return &RepricingJobHandler{jobService: jobService}
+}
+
+// repricingJobError maps a job error to a response
+func repricingJobError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, outbox.ErrEventNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, outbox.ErrEventNotFailed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// GetJobs handles listing repricing jobs by ?status, dead-lettered (failed) ones by default,
+// capped by ?limit
+func (h *RepricingJobHandler) GetJobs(c echo.Context) error {
+	status := repo.OutboxStatus(c.QueryParam("status"))
+	switch status {
+	case "":
+		status = repo.OutboxStatusFailed
+	case "all":
+		status = ""
+	case repo.OutboxStatusPending, repo.OutboxStatusDone, repo.OutboxStatusFailed, repo.OutboxStatusDiscarded:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
+
+	limit := defaultRepricingJobsLimit
+	if param := c.QueryParam("limit"); param != "" {
+		l, err := strconv.Atoi(param)
+		if err != nil || l <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = l
+	}
+
+	jobs, err := h.jobService.GetJobs(c.Request().Context(), status, limit)
+	if err != nil {
+		return repricingJobError(c, err)
+	}
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// RetryJob handles queueing a dead-lettered job again
+func (h *RepricingJobHandler) RetryJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	job, err := h.jobService.RetryJob(c.Request().Context(), id)
+	if err != nil {
+		return repricingJobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// DiscardJob handles dropping a dead-lettered job
+func (h *RepricingJobHandler) DiscardJob(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid job ID"})
+	}
+
+	job, err := h.jobService.DiscardJob(c.Request().Context(), id)
+	if err != nil {
+		return repricingJobError(c, err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// GetStats handles retrieving the sale repricing counters: sales received, how many were
+// coalesced into another reprice, and the reprices executed and failed
+func (h *RepricingJobHandler) GetStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.jobService.GetStats())
+}
@@ -0,0 +1,108 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/labstack/echo/v4"
+)
+
+type PriceScheduleHandler struct {
+	scheduleService *adminSvc.PriceScheduleService
+}
+
+func NewPriceScheduleHandler(scheduleService *adminSvc.PriceScheduleService) *PriceScheduleHandler {
+	return &PriceScheduleHandler{scheduleService: scheduleService}
+}
+
+// scheduleError maps a schedule error to a response
+func scheduleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, pricing.ErrScheduleNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, pricing.ErrScheduleClosed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// CreateSchedule handles scheduling a base or adjusted price change.
+// starts_at and ends_at are RFC 3339 timestamps; ends_at makes the change temporary.
+func (h *PriceScheduleHandler) CreateSchedule(c echo.Context) error {
+	schedule := repo.PriceSchedule{PriceType: repo.PriceTypeBase}
+	if err := c.Bind(&schedule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+	if username, ok := c.Get("username").(string); ok {
+		schedule.CreatedBy = &username
+	}
+
+	createdSchedule, err := h.scheduleService.CreateSchedule(c.Request().Context(), &schedule)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, createdSchedule)
+}
+
+// GetSchedules handles listing schedules, filtered by ?status and ?product_id
+func (h *PriceScheduleHandler) GetSchedules(c echo.Context) error {
+	status := repo.ScheduleStatus(c.QueryParam("status"))
+	switch status {
+	case "", repo.ScheduleStatusScheduled, repo.ScheduleStatusActive, repo.ScheduleStatusCompleted,
+		repo.ScheduleStatusCancelled, repo.ScheduleStatusFailed:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
+
+	productId := 0
+	if param := c.QueryParam("product_id"); param != "" {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+		}
+		productId = id
+	}
+
+	schedules, err := h.scheduleService.GetSchedules(c.Request().Context(), status, productId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, schedules)
+}
+
+// GetScheduleByID handles retrieving a schedule by its ID
+func (h *PriceScheduleHandler) GetScheduleByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+	}
+
+	schedule, err := h.scheduleService.GetScheduleByID(c.Request().Context(), id)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// CancelSchedule handles cancelling a schedule, reverting it if it is already in effect
+func (h *PriceScheduleHandler) CancelSchedule(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid schedule ID"})
+	}
+
+	schedule, err := h.scheduleService.CancelSchedule(c.Request().Context(), id)
+	if err != nil {
+		return scheduleError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
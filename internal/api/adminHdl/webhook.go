@@ -0,0 +1,156 @@
+package adminHdl
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
+	"github.com/Daniel-Njaramba-1/pulse/internal/webhooks"
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDeliveriesLimit caps how many deliveries are listed when no limit is given
+const defaultDeliveriesLimit = 100
+
+type WebhookHandler struct {
+	webhookService *adminSvc.WebhookService
+}
+
+func NewWebhookHandler(webhookService *adminSvc.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// webhookError maps a webhook error to a response
+func webhookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, webhooks.ErrWebhookNotFound), errors.Is(err, webhooks.ErrDeliveryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, webhooks.ErrDeliveryPending):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// CreateWebhook handles registering a webhook with its url, event_types and optional secret.
+// The response is the only one that includes the secret.
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	webhook := repo.Webhook{IsActive: true}
+	if err := c.Bind(&webhook); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+	if username, ok := c.Get("username").(string); ok {
+		webhook.CreatedBy = &username
+	}
+
+	createdWebhook, err := h.webhookService.CreateWebhook(c.Request().Context(), &webhook)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, createdWebhook)
+}
+
+// GetWebhooks handles listing every webhook
+func (h *WebhookHandler) GetWebhooks(c echo.Context) error {
+	hooks, err := h.webhookService.GetWebhooks(c.Request().Context())
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, hooks)
+}
+
+// GetWebhookByID handles retrieving a webhook by its ID
+func (h *WebhookHandler) GetWebhookByID(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	webhook, err := h.webhookService.GetWebhookByID(c.Request().Context(), id)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook handles changing a webhook; a secret in the body rotates it
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+	webhook := repo.Webhook{IsActive: true}
+	if err := c.Bind(&webhook); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid input"})
+	}
+	webhook.Id = id
+
+	updatedWebhook, err := h.webhookService.UpdateWebhook(c.Request().Context(), &webhook)
+	if errors.Is(err, webhooks.ErrWebhookNotFound) {
+		return webhookError(c, err)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, updatedWebhook)
+}
+
+// DeleteWebhook handles removing a webhook and its delivery log
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.Request().Context(), id); err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "webhook deleted successfully"})
+}
+
+// GetDeliveries handles listing a webhook's deliveries by ?status, capped by ?limit
+func (h *WebhookHandler) GetDeliveries(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid webhook ID"})
+	}
+
+	status := repo.DeliveryStatus(c.QueryParam("status"))
+	switch status {
+	case "", repo.DeliveryStatusPending, repo.DeliveryStatusDelivered, repo.DeliveryStatusFailed:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid status"})
+	}
+
+	limit := defaultDeliveriesLimit
+	if param := c.QueryParam("limit"); param != "" {
+		l, err := strconv.Atoi(param)
+		if err != nil || l <= 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+		}
+		limit = l
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(c.Request().Context(), id, status, limit)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver handles sending a delivered or failed delivery again
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid delivery ID"})
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request().Context(), id)
+	if err != nil {
+		return webhookError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,51 @@
+package customerHdl
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/events"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/customerSvc"
+	"github.com/labstack/echo/v4"
+)
+
+type EventHandler struct {
+	hub            *events.Hub
+	allowedOrigins []string
+}
+
+func NewEventHandler(hub *events.Hub, allowedOrigins []string) *EventHandler {
+	return &EventHandler{hub: hub, allowedOrigins: allowedOrigins}
+}
+
+// Stream streams the public live events plus the customer's own order statuses,
+// filtered by ?topics, ?product_ids and ?category_ids
+func (h *EventHandler) Stream(c echo.Context) error {
+	userId := c.Get("userId").(int)
+	return events.ServeSSE(c, h.hub, events.AudienceCustomer, userId)
+}
+
+// StreamPublic streams the public live events to anyone, filtered by ?topics,
+// ?product_ids and ?category_ids
+func (h *EventHandler) StreamPublic(c echo.Context) error {
+	return events.ServeSSE(c, h.hub, events.AudiencePublic, 0)
+}
+
+// StreamWS serves live events over a WebSocket to pages on the allowed origins. Browsers
+// cannot set headers on a WebSocket, so the customer token may come as a "bearer.<token>"
+// subprotocol, offered alongside events.WSProtocol, as well as in the Authorization header;
+// without one the connection only gets the public events.
+func (h *EventHandler) StreamWS(c echo.Context) error {
+	tokenString := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if tokenString == "" {
+		tokenString = events.WSToken(c.Request())
+	}
+	if tokenString == "" {
+		return events.ServeWS(c, h.hub, events.AudiencePublic, 0, h.allowedOrigins)
+	}
+	claims, err := customerSvc.VerifyCustomerToken(tokenString)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	return events.ServeWS(c, h.hub, events.AudienceCustomer, claims.Id, h.allowedOrigins)
+}
@@ -1,8 +1,11 @@
 package customerHdl
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
 	"github.com/Daniel-Njaramba-1/pulse/internal/services/customerSvc"
 	"github.com/labstack/echo/v4"
 )
@@ -15,6 +18,11 @@ func NewOrderHandler(orderService *customerSvc.OrderService) *OrderHandler {
 	return &OrderHandler{orderService: orderService}
 }
 
+const (
+	defaultOrderPageSize = 20
+	maxOrderPageSize     = 100
+)
+
 func (h *OrderHandler) GenerateOrder(c echo.Context) error {
 	// Get the user ID from the context (assuming it's set during authentication)
 	userId := c.Get("userId").(int)
@@ -39,3 +47,66 @@ func (h *OrderHandler) GetOrderWithItems (c echo.Context) error {
 	return c.JSON(http.StatusOK, orderWithItems)
 }
 
+
+// GetOrders handles listing the customer's orders, newest first, filtered by ?status, ?from
+// and ?to and paged by ?page and ?page_size
+func (h *OrderHandler) GetOrders(c echo.Context) error {
+	userId := c.Get("userId").(int)
+
+	filter, err := orders.ParseFilter(c.QueryParams(), defaultOrderPageSize, maxOrderPageSize)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	page, err := h.orderService.GetOrders(c.Request().Context(), userId, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// GetOrder handles retrieving one of the customer's orders with its items and payments
+func (h *OrderHandler) GetOrder(c echo.Context) error {
+	userId := c.Get("userId").(int)
+
+	orderId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+	}
+
+	order, err := h.orderService.GetOrder(c.Request().Context(), userId, orderId)
+	if err != nil {
+		if errors.Is(err, customerSvc.ErrOrderNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+// CancelOrder handles cancelling one of the customer's pending orders, restoring its cart items.
+// Orders past pending cannot be cancelled.
+func (h *OrderHandler) CancelOrder(c echo.Context) error {
+	userId := c.Get("userId").(int)
+
+	orderId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid order ID"})
+	}
+
+	order, err := h.orderService.CancelOrder(c.Request().Context(), userId, orderId)
+	if err != nil {
+		switch {
+		case errors.Is(err, customerSvc.ErrOrderNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, orders.ErrInvalidTransition):
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
@@ -1,9 +1,11 @@
 package customerHdl
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/services/customerSvc"
 	"github.com/labstack/echo/v4"
 )
@@ -53,4 +55,55 @@ func (h *ProductHandler) GetProductByName(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, product)
-}
\ No newline at end of file
+}
+
+// ExplainPrice shows how the pricing model arrived at a product's price
+func (h *ProductHandler) ExplainPrice(c echo.Context) error {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+	}
+
+	explanation, err := h.productService.ExplainPrice(c.Request().Context(), productID)
+	if err != nil {
+		if errors.Is(err, pricing.ErrNoPricingData) || errors.Is(err, pricing.ErrNoActiveModel) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, explanation)
+}
+
+// GetPriceHistory returns a product's price history.
+// ?interval is day (default) or week; ?days defaults to 30 for days and 84 for weeks.
+func (h *ProductHandler) GetPriceHistory(c echo.Context) error {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid product ID"})
+	}
+
+	interval := c.QueryParam("interval")
+	if interval == "" {
+		interval = customerSvc.PriceIntervalDay
+	}
+	days := 30
+	if interval == customerSvc.PriceIntervalWeek {
+		days = 84
+	}
+	if param := c.QueryParam("days"); param != "" {
+		if days, err = strconv.Atoi(param); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid days"})
+		}
+	}
+
+	history, err := h.productService.GetPriceHistory(c.Request().Context(), productID, interval, days)
+	if err != nil {
+		if errors.Is(err, customerSvc.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
@@ -21,6 +21,20 @@ func AdminRoutes(e *echo.Echo, adminHandlers *AdminHdl) {
 		return adminHandlers.CustomerHandler.GetAllCustomers(c)
 	})
 
+	// Order routes
+	protected.GET("/orders", func(c echo.Context) error {
+		return adminHandlers.OrderHandler.GetOrders(c)
+	})
+	protected.GET("/orders/:id", func(c echo.Context) error {
+		return adminHandlers.OrderHandler.GetOrderByID(c)
+	})
+	protected.GET("/orders/:id/history", func(c echo.Context) error {
+		return adminHandlers.OrderHandler.GetOrderHistory(c)
+	})
+	protected.POST("/orders/:id/status", func(c echo.Context) error {
+		return adminHandlers.OrderHandler.UpdateOrderStatus(c)
+	})
+
 	// Brand routes
 	protected.GET("/brands", func(c echo.Context) error {
 		return adminHandlers.BrandHandler.GetAllBrands(c)
@@ -98,6 +112,183 @@ func AdminRoutes(e *echo.Echo, adminHandlers *AdminHdl) {
 	protected.PUT("/products/:id/restock", func(c echo.Context) error {
 		return adminHandlers.ProductHandler.UpdateProductStock(c)
 	})
+	protected.PUT("/products/:id/cost", func(c echo.Context) error {
+		return adminHandlers.ProductHandler.UpdateProductCost(c)
+	})
+	protected.GET("/products/:id/pricing-policy", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.GetEffectivePolicy(c)
+	})
+	protected.GET("/products/:id/price-explanation", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.ExplainPrice(c)
+	})
+
+	// Pricing policy routes
+	protected.GET("/pricing-policies", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.GetAllPolicies(c)
+	})
+	protected.GET("/pricing-policies/rejections", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.GetRejections(c)
+	})
+	protected.GET("/pricing-policies/:id", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.GetPolicyByID(c)
+	})
+	protected.POST("/pricing-policies", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.CreatePolicy(c)
+	})
+	protected.PUT("/pricing-policies/:id", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.UpdatePolicy(c)
+	})
+	protected.DELETE("/pricing-policies/:id", func(c echo.Context) error {
+		return adminHandlers.PricingPolicyHandler.DeletePolicy(c)
+	})
+
+	// Price proposal routes
+	protected.GET("/pricing/proposals", func(c echo.Context) error {
+		return adminHandlers.PricingProposalHandler.GetProposals(c)
+	})
+	protected.GET("/pricing/proposals/:id", func(c echo.Context) error {
+		return adminHandlers.PricingProposalHandler.GetProposalByID(c)
+	})
+	protected.POST("/pricing/proposals/:id/approve", func(c echo.Context) error {
+		return adminHandlers.PricingProposalHandler.ApproveProposal(c)
+	})
+	protected.POST("/pricing/proposals/:id/reject", func(c echo.Context) error {
+		return adminHandlers.PricingProposalHandler.RejectProposal(c)
+	})
+	protected.POST("/pricing/proposals/approve", func(c echo.Context) error {
+		return adminHandlers.PricingProposalHandler.ApproveProposals(c)
+	})
+	protected.POST("/pricing/proposals/reject", func(c echo.Context) error {
+		return adminHandlers.PricingProposalHandler.RejectProposals(c)
+	})
+
+	// Model registry routes
+	protected.GET("/pricing/models", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.GetModels(c)
+	})
+	protected.POST("/pricing/models/rollback", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.RollbackModel(c)
+	})
+	protected.POST("/pricing/models/train", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.TrainModel(c)
+	})
+	protected.GET("/pricing/models/:version", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.GetModel(c)
+	})
+	protected.POST("/pricing/models/:version/promote", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.PromoteModel(c)
+	})
+	protected.POST("/pricing/models/:version/shadow", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.ShadowModel(c)
+	})
+	protected.POST("/pricing/models/:version/retire", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.RetireModel(c)
+	})
+	protected.GET("/pricing/models/:version/shadow-report", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.GetShadowReport(c)
+	})
+	protected.GET("/pricing/models/:version/training-set", func(c echo.Context) error {
+		return adminHandlers.ModelRegistryHandler.GetTrainingSet(c)
+	})
+
+	// Price experiment routes
+	protected.GET("/pricing/experiments", func(c echo.Context) error {
+		return adminHandlers.PriceExperimentHandler.GetExperiments(c)
+	})
+	protected.POST("/pricing/experiments", func(c echo.Context) error {
+		return adminHandlers.PriceExperimentHandler.CreateExperiment(c)
+	})
+	protected.GET("/pricing/experiments/:id", func(c echo.Context) error {
+		return adminHandlers.PriceExperimentHandler.GetExperimentByID(c)
+	})
+	protected.POST("/pricing/experiments/:id/stop", func(c echo.Context) error {
+		return adminHandlers.PriceExperimentHandler.StopExperiment(c)
+	})
+	protected.GET("/pricing/experiments/:id/report", func(c echo.Context) error {
+		return adminHandlers.PriceExperimentHandler.GetExperimentReport(c)
+	})
+
+	// Price schedule routes
+	protected.GET("/pricing/schedules", func(c echo.Context) error {
+		return adminHandlers.PriceScheduleHandler.GetSchedules(c)
+	})
+	protected.POST("/pricing/schedules", func(c echo.Context) error {
+		return adminHandlers.PriceScheduleHandler.CreateSchedule(c)
+	})
+	protected.GET("/pricing/schedules/:id", func(c echo.Context) error {
+		return adminHandlers.PriceScheduleHandler.GetScheduleByID(c)
+	})
+	protected.POST("/pricing/schedules/:id/cancel", func(c echo.Context) error {
+		return adminHandlers.PriceScheduleHandler.CancelSchedule(c)
+	})
+
+	// Live event stream
+	protected.GET("/events", func(c echo.Context) error {
+		return adminHandlers.EventHandler.Stream(c)
+	})
+	protected.GET("/events/stats", func(c echo.Context) error {
+		return adminHandlers.EventHandler.GetStats(c)
+	})
+
+	// Repricing job routes
+	protected.GET("/repricing/stats", func(c echo.Context) error {
+		return adminHandlers.RepricingJobHandler.GetStats(c)
+	})
+	protected.GET("/repricing/jobs", func(c echo.Context) error {
+		return adminHandlers.RepricingJobHandler.GetJobs(c)
+	})
+	protected.POST("/repricing/jobs/:id/retry", func(c echo.Context) error {
+		return adminHandlers.RepricingJobHandler.RetryJob(c)
+	})
+	protected.POST("/repricing/jobs/:id/discard", func(c echo.Context) error {
+		return adminHandlers.RepricingJobHandler.DiscardJob(c)
+	})
+
+	// Webhook routes
+	protected.GET("/webhooks", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.GetWebhooks(c)
+	})
+	protected.POST("/webhooks", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.CreateWebhook(c)
+	})
+	protected.GET("/webhooks/:id", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.GetWebhookByID(c)
+	})
+	protected.PUT("/webhooks/:id", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.UpdateWebhook(c)
+	})
+	protected.DELETE("/webhooks/:id", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.DeleteWebhook(c)
+	})
+	protected.GET("/webhooks/:id/deliveries", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.GetDeliveries(c)
+	})
+	protected.POST("/webhooks/deliveries/:id/redeliver", func(c echo.Context) error {
+		return adminHandlers.WebhookHandler.Redeliver(c)
+	})
+
+	// Background job routes
+	protected.GET("/jobs", func(c echo.Context) error {
+		return adminHandlers.JobHandler.GetJobs(c)
+	})
+	protected.GET("/jobs/:id", func(c echo.Context) error {
+		return adminHandlers.JobHandler.GetJobByID(c)
+	})
+	protected.GET("/jobs/:id/runs", func(c echo.Context) error {
+		return adminHandlers.JobHandler.GetRuns(c)
+	})
+	protected.POST("/jobs/:id/trigger", func(c echo.Context) error {
+		return adminHandlers.JobHandler.TriggerJob(c)
+	})
+	protected.POST("/jobs/:id/pause", func(c echo.Context) error {
+		return adminHandlers.JobHandler.PauseJob(c)
+	})
+	protected.POST("/jobs/:id/resume", func(c echo.Context) error {
+		return adminHandlers.JobHandler.ResumeJob(c)
+	})
+	protected.PUT("/jobs/:id/schedule", func(c echo.Context) error {
+		return adminHandlers.JobHandler.UpdateSchedule(c)
+	})
 
 	// Dashboard routes
 	protected.GET("/dashboard/coefficients", func(c echo.Context) error {
@@ -130,4 +321,13 @@ func AdminRoutes(e *echo.Echo, adminHandlers *AdminHdl) {
 	protected.GET("/dashboard/category-revenue", func(c echo.Context) error {
 		return adminHandlers.DashboardHandler.GetCategoryRevenue(c)
 	})
+	protected.POST("/dashboard/backtest", func(c echo.Context) error {
+		return adminHandlers.DashboardHandler.RunBacktest(c)
+	})
+	protected.GET("/dashboard/elasticity", func(c echo.Context) error {
+		return adminHandlers.DashboardHandler.GetElasticities(c)
+	})
+	protected.POST("/dashboard/elasticity/estimate", func(c echo.Context) error {
+		return adminHandlers.DashboardHandler.EstimateElasticities(c)
+	})
 }
@@ -3,15 +3,26 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"syscall"
 	"time"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/config"
 	"github.com/Daniel-Njaramba-1/pulse/internal/db"
-	"github.com/go-co-op/gocron"
+	"github.com/Daniel-Njaramba-1/pulse/internal/events"
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/scheduler"
+	"github.com/Daniel-Njaramba-1/pulse/internal/services/customerSvc"
+	"github.com/Daniel-Njaramba-1/pulse/internal/webhooks"
 	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -22,76 +33,174 @@ type App struct {
 	db            *sqlx.DB
 	echo          *echo.Echo
 	dbConfig      *db.DBConfig
+	pricing       pricing.PricingBackend
+	hub           *events.Hub
+	workers       *Lifecycle
+	shutdownTimeout time.Duration
 }
 
-// startScheduledJobs initializes and starts scheduled background jobs
-func startScheduledJobs() {
-	s := gocron.NewScheduler(time.UTC)
+// allowedOrigins are the frontends allowed to call the API, by CORS and on the WebSocket
+var allowedOrigins = []string{"http://localhost:5185", "http://localhost:5190", "http://localhost:5195"}
 
-	// Daily price adjustment job (calls Python API)
-	s.Every(1).Day().At("00:00").Do(func() {
-		log.Println("Running daily price adjustment job (Python API)")
-		resp, err := http.Post("http://localhost:5872/adjust-prices", "application/json", nil)
+// DefaultShutdownTimeout is how long shutting down may take before the app gives up waiting
+const DefaultShutdownTimeout = 30 * time.Second
+
+// loadShutdownTimeout reads SHUTDOWN_TIMEOUT_SECONDS, defaulting to DefaultShutdownTimeout
+func loadShutdownTimeout() (time.Duration, error) {
+	param := config.GetEnv("SHUTDOWN_TIMEOUT_SECONDS")
+	if param == "" {
+		return DefaultShutdownTimeout, nil
+	}
+	seconds, err := strconv.Atoi(param)
+	if err != nil || seconds <= 0 {
+		return 0, fmt.Errorf("invalid SHUTDOWN_TIMEOUT_SECONDS: %q", param)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// registerJobs defines the background jobs and their default schedules, in UTC, running them
+// through the same services the API uses
+func registerJobs(s *scheduler.Scheduler, backend pricing.PricingBackend, adminServices *AdminServices, customerServices *CustomerServices) {
+	// Daily price adjustment job
+	s.Register("adjust-prices", "Reprice every product with the active model", "0 0 * * *", func(ctx context.Context) (string, error) {
+		count, err := backend.AdjustAll(ctx)
 		if err != nil {
-			log.Printf("Price adjustment API call failed: %v", err)
-			return
+			return "", err
 		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			log.Printf("Price adjustment API returned status: %v", resp.Status)
-		} else {
-			log.Println("Price adjustment API call succeeded")
+		return fmt.Sprintf("%d products repriced", count), nil
+	})
+
+	// Scheduled price change job, applying and reverting due price schedules
+	s.Register("price-schedules", "Apply and revert due price schedules", "* * * * *", func(ctx context.Context) (string, error) {
+		applied, reverted, err := adminServices.priceScheduleService.RunDue(ctx)
+		if err != nil {
+			return "", err
 		}
+		return fmt.Sprintf("%d applied, %d reverted", applied, reverted), nil
 	})
 
-	// Monthly model training job (calls Python API)
-	s.Every(1).Month(1).At("01:00").Do(func() {
-		log.Println("Running monthly model training job (Python API)")
-		resp, err := http.Post("http://localhost:5872/train-model", "application/json", nil)
+	// Daily elasticity estimation job
+	s.Register("estimate-elasticities", "Estimate price elasticities from recent sales", "30 0 * * *", func(ctx context.Context) (string, error) {
+		summary, err := adminServices.dashboardService.EstimateElasticities(ctx, pricing.DefaultElasticityWindowDays)
 		if err != nil {
-			log.Printf("Model training API call failed: %v", err)
-			return
+			return "", err
 		}
-		defer resp.Body.Close()
-		if resp.StatusCode != http.StatusOK {
-			log.Printf("Model training API returned status: %v", resp.Status)
-		} else {
-			log.Println("Model training API call succeeded")
+		return fmt.Sprintf("%d products, %d categories", summary.Products, summary.Categories), nil
+	})
+
+	// Stale order sweep, expiring pending orders abandoned long past their price validity
+	s.Register("expire-orders", "Expire abandoned pending orders and restore their carts", "*/15 * * * *", func(ctx context.Context) (string, error) {
+		count, err := customerServices.orderService.ExpireStaleOrders(ctx, customerSvc.StaleOrderGrace)
+		if err != nil {
+			return "", err
 		}
+		return fmt.Sprintf("%d orders expired", count), nil
 	})
 
-	s.StartAsync()
+	// Monthly model training job
+	s.Register("train-model", "Train a new pricing model", "0 1 1 * *", func(ctx context.Context) (string, error) {
+		return backend.Train(ctx)
+	})
+}
+
+// repriceOnSale recomputes the features of a product that just sold and reprices it, once for
+// every burst of sales of the product. Work turned away by an open circuit breaker is put off
+// until the breaker tries again.
+func repriceOnSale(repricer *pricing.Repricer) outbox.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var sale outbox.SaleEvent
+		if err := json.Unmarshal(payload, &sale); err != nil {
+			return fmt.Errorf("invalid sale event: %w", err)
+		}
+		err := repricer.Reprice(ctx, sale.ProductId)
+		var circuitOpen *pricing.CircuitOpenError
+		if errors.As(err, &circuitOpen) {
+			return outbox.Postpone(err, circuitOpen.RetryAfter)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reprice product %d: %w", sale.ProductId, err)
+		}
+		log.Printf("Repriced product %d after sale %d", sale.ProductId, sale.SaleId)
+		return nil
+	}
 }
 
 // NewApp initializes and returns a new app instance
 func NewApp() (*App, error) {
 	ctx := context.Background()
 	
+	// Load every config before connecting, so that a bad setting fails fast
 	dbConfig, err := db.LoadDBConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load DB config: %w", err)
 	}
+	shutdownTimeout, err := loadShutdownTimeout()
+	if err != nil {
+		return nil, err
+	}
+	hubConfig, err := events.LoadHubConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event hub config: %w", err)
+	}
+	pricingConfig, err := pricing.LoadBackendConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing config: %w", err)
+	}
 	
 	database, err := db.InitDB(ctx, dbConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
-	// Start client manager in a goroutine
-	go db.Manager.Run()
-	
-	// Start price adjustment listener in a goroutine
+	// Pricing backend shared by the sale event handler and the scheduled jobs. This is the last
+	// step that can fail; nothing below has to be undone.
+	pricingBackend, err := pricing.NewBackend(pricingConfig, database)
+	if err != nil {
+		db.CloseDB(database)
+		return nil, fmt.Errorf("failed to create pricing backend: %w", err)
+	}
+	log.Printf("Using %s pricing backend", pricingConfig.Kind)
+	if pricingConfig.Review.Enabled {
+		log.Printf("Pricing review mode on, auto-approving changes up to %.2f%%", pricingConfig.Review.AutoApprovePct)
+	}
+	if pricingConfig.ElasticityCap.Threshold > 0 {
+		log.Printf("Capping increases at %.2f%% for products with elasticity at or below -%.2f",
+			pricingConfig.ElasticityCap.MaxIncreasePct, pricingConfig.ElasticityCap.Threshold)
+	}
+
+	// Start the price adjustment and stock and order listeners, publishing to the event hub
+	hub := events.NewHub(hubConfig)
+	log.Printf("Event hub queues %d events per client, dropping by %s policy", hubConfig.BufferSize, hubConfig.DropPolicy)
 	connStr := db.BuildConnStr(dbConfig)
-	go db.StartPriceAdjustmentListener(connStr)
+	workers := NewLifecycle()
+	workers.Go("price adjustment listener", func(ctx context.Context) {
+		db.StartPriceAdjustmentListener(ctx, connStr, hub)
+	})
+	workers.Go("event listener", func(ctx context.Context) {
+		db.StartEventListener(ctx, connStr, hub)
+	})
+
+	repricer := pricing.NewRepricer(pricingBackend, pricingConfig.Repricer)
+	workers.Go("repricer", repricer.Run)
+	log.Printf("Coalescing sale repricing over %s, %d products at a time",
+		pricingConfig.Repricer.Window, pricingConfig.Repricer.Concurrency)
+	if pricingConfig.Kind == pricing.BackendHTTP && pricingConfig.Breaker.Threshold > 0 {
+		log.Printf("Pricing circuit breaker opens for %s after %d failures in a row",
+			pricingConfig.Breaker.Cooldown, pricingConfig.Breaker.Threshold)
+	}
 
-	// start cron job
-	startScheduledJobs()
-	log.Printf("Started jobs: Price adjustment and Model Training")
+	// Run the background jobs on their schedules, on whichever instance leads
+	jobScheduler := scheduler.NewScheduler(database)
+	adminServices := NewAdminServices(database, repricer, jobScheduler)
+	customerServices := NewCustomerServices(database)
+	registerJobs(jobScheduler, pricingBackend, adminServices, customerServices)
+	workers.Go("job scheduler", jobScheduler.Run)
+	log.Printf("Started job scheduler: Price adjustment, Price schedules, Elasticity estimation, Order expiry and Model Training")
 
 	// Initialize Echo framework
 	e := echo.New()
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"http://localhost:5185", "http://localhost:5190", "http://localhost:5195"},
+		AllowOrigins: allowedOrigins,
 		AllowMethods: []string{echo.GET, echo.POST, echo.PUT, echo.DELETE, echo.OPTIONS},
 		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, echo.HeaderCookie},
 		AllowCredentials: true,
@@ -100,18 +209,26 @@ func NewApp() (*App, error) {
 	e.Use(middleware.Recover())
 
 	// Set up SSE endpoint
-	e.GET("/api/price-adjustments", HandleSSE)
+	e.GET("/api/price-adjustments", func(c echo.Context) error {
+		return HandleSSE(c, hub)
+	})
 
 	// Set up service handlers
-	adminServices := NewAdminServices(database)
-	customerServices := NewCustomerServices(database)
-
-	adminHandlers := NewAdminHdl(adminServices)
-	customerHandlers := NewCustomerHdl(customerServices)
+	adminHandlers := NewAdminHdl(adminServices, hub)
+	customerHandlers := NewCustomerHdl(customerServices, hub, allowedOrigins)
 
 	AdminRoutes(e, adminHandlers)
 	CustomerRoutes(e, customerHandlers)
 
+	// Public live event stream; signed-in customers and admins have their own
+	e.GET("/api/events", func(c echo.Context) error {
+		return customerHandlers.EventHandler.StreamPublic(c)
+	})
+	// The same events over a WebSocket, with the customer's own when signed in
+	e.GET("/api/ws", func(c echo.Context) error {
+		return customerHandlers.EventHandler.StreamWS(c)
+	})
+
 	// Set up static file serving for product images using Go's built-in file server
     rootDir, err := os.Getwd()
     if err != nil {
@@ -126,13 +243,28 @@ func NewApp() (*App, error) {
         log.Printf("Serving static files from: %s", productImagesPath)
     }
 	
-	// Start Price Adjustment
-	go db.StartSaleListener(connStr)
+	// Dispatch outbox events, repricing products as they sell. A whole batch is handled at
+	// once so that sales of the same product can be coalesced.
+	dispatcher := outbox.NewDispatcher(database)
+	dispatcher.Workers = dispatcher.BatchSize
+	dispatcher.Handle(outbox.TopicSale, repriceOnSale(repricer))
+
+	// Send the deliveries the database records for subscribed webhooks, retried by the dispatcher
+	deliverer := webhooks.NewDeliverer(database)
+	deliverer.MaxAttempts = dispatcher.MaxAttempts
+	dispatcher.Handle(outbox.TopicWebhook, deliverer.Deliver)
+	workers.Go("outbox dispatcher", func(ctx context.Context) {
+		dispatcher.Run(ctx, connStr)
+	})
 	
 	return &App{
 		db:           database,
 		echo:         e,
 		dbConfig:     dbConfig,
+		pricing:      pricingBackend,
+		hub:          hub,
+		workers:      workers,
+		shutdownTimeout: shutdownTimeout,
 	}, nil
 }
 
@@ -141,6 +273,49 @@ func (a *App) Start() error {
 	return a.echo.Start(":8080")
 }
 
+// Run serves HTTP requests until the process gets SIGINT or SIGTERM, or the server fails, and
+// then shuts the app down within its shutdown timeout
+func (a *App) Run() error {
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- a.Start()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var runErr error
+	select {
+	case sig := <-signals:
+		log.Printf("Received %s, shutting down", sig)
+	case err := <-serverErr:
+		runErr = fmt.Errorf("server stopped: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+	return errors.Join(runErr, a.Shutdown(ctx))
+}
+
+// Shutdown stops taking requests, ends the live event streams so that their connections can
+// drain with the other in-flight requests, and then stops the background workers. It gives up
+// waiting when ctx is done.
+func (a *App) Shutdown(ctx context.Context) error {
+	var errs []error
+	a.hub.Close()
+	if err := a.echo.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain HTTP requests: %w", err))
+	}
+	if err := a.workers.Stop(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		log.Printf("Shut down cleanly")
+	}
+	return errors.Join(errs...)
+}
+
 // Close properly shuts down the application
 func (a *App) Close() {
 	if a.db != nil {
@@ -153,61 +328,32 @@ func (a *App) GetDB() *sqlx.DB {
 	return a.db
 }
 
+// GetPricing returns the pricing backend
+func (a *App) GetPricing() pricing.PricingBackend {
+	return a.pricing
+}
 
 
-// HandleSSE handles Server-Sent Events connections
-func HandleSSE(c echo.Context) error {
-	c.Response().Header().Set("Content-Type", "text/event-stream")
-    c.Response().Header().Set("Cache-Control", "no-cache")
-    c.Response().Header().Set("Connection", "keep-alive")
-    c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 
-	c.Response().WriteHeader(http.StatusOK)
+// HandleSSE streams price adjustments as unnamed Server-Sent Events carrying the adjustment.
+// Each has an id, so a client reconnecting with Last-Event-ID gets the adjustments it missed.
+func HandleSSE(c echo.Context, hub *events.Hub) error {
+	c.Response().Header().Set("Access-Control-Allow-Origin", "*")
 
+	filter := events.Filter{
+		Audience: events.AudiencePublic,
+		Topics:   map[events.Topic]bool{events.TopicPriceChange: true},
+	}
 	initialData := map[string]string{
-        "type": "connection",
-        "status": "established",
-        "timestamp": fmt.Sprintf("%d", time.Now().Unix()),
-    }
-    initialJSON, _ := json.Marshal(initialData)
-    fmt.Fprintf(c.Response().Writer, "event: connect\ndata: %s\n\n", initialJSON)
-    c.Response().Flush()
+		"type":      "connection",
+		"status":    "established",
+		"timestamp": fmt.Sprintf("%d", time.Now().Unix()),
+	}
 	log.Printf("SSE client connected from %s", c.Request().RemoteAddr)
+	defer log.Printf("SSE client connection closed")
 
-    // Each client gets its own channel
-    messageChan := make(chan string)
-    
-    // Register this client
-    db.Manager.RegisterChannel(messageChan)
-    
-    // Ensure client is unregistered when connection closes
-    defer func() {
-        db.Manager.UnregisterChannel(messageChan)
-    }()
-
-	// Send a heartbeat every 30 seconds to keep connection alive
-	heartbeat := time.NewTicker(30 * time.Second)
-    defer heartbeat.Stop()
-    
-    // Keep connection open
-    for {
-        select {
-        case msg := <-messageChan:
-            // Format as SSE
-            if _, err := fmt.Fprintf(c.Response().Writer, "data: %s\n\n", msg); err != nil {
-                return err
-            }
-            c.Response().Flush()
-		case <-heartbeat.C:
-            // Send heartbeat comment to keep connection alive
-            if _, err := fmt.Fprintf(c.Response().Writer, ": heartbeat %v\n\n", time.Now().Unix()); err != nil {
-                log.Printf("Error sending SSE heartbeat: %v", err)
-                return err
-            }
-            c.Response().Flush()
-        case <-c.Request().Context().Done():
-            log.Printf("SSE client connection closed")
-			return nil
-        }
-    }
-}
\ No newline at end of file
+	return events.Stream(c, hub, filter, initialData, func(w io.Writer, e *events.Event) error {
+		_, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.Id, e.Data)
+		return err
+	})
+}
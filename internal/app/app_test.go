@@ -0,0 +1,92 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+)
+
+func saleEvent(t *testing.T, saleId, productId int) json.RawMessage {
+	t.Helper()
+	payload, err := json.Marshal(outbox.SaleEvent{SaleId: saleId, ProductId: productId, SalePrice: 10, Quantity: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func newSaleHandler(fake *pricing.FakeBackend, breaker pricing.BreakerConfig) outbox.Handler {
+	backend := pricing.NewCircuitBreaker(fake, breaker)
+	repricer := pricing.NewRepricer(backend, pricing.RepricerConfig{Window: 20 * time.Millisecond, Concurrency: 2})
+	return repriceOnSale(repricer)
+}
+
+func TestRepriceOnSaleCoalescesSales(t *testing.T) {
+	fake := pricing.NewFakeBackend()
+	handle := newSaleHandler(fake, pricing.BreakerConfig{Threshold: 5, Cooldown: time.Minute})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = handle(context.Background(), saleEvent(t, i+1, 7))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("sale %d: handler error = %v, want nil", i+1, err)
+		}
+	}
+	if len(fake.ComputedProducts) != 1 || len(fake.AdjustedProducts) != 1 || fake.AdjustedProducts[0] != 7 {
+		t.Errorf("computed %v and adjusted %v, want product 7 repriced once", fake.ComputedProducts, fake.AdjustedProducts)
+	}
+}
+
+func TestRepriceOnSaleFailure(t *testing.T) {
+	fake := pricing.NewFakeBackend()
+	fake.ComputeFeaturesErr = errors.New("no pricing data found for product 7")
+	handle := newSaleHandler(fake, pricing.BreakerConfig{Threshold: 5, Cooldown: time.Minute})
+
+	err := handle(context.Background(), saleEvent(t, 1, 7))
+	if !errors.Is(err, fake.ComputeFeaturesErr) {
+		t.Fatalf("handler error = %v, want the backend error", err)
+	}
+	if _, postponed := outbox.Postponed(err); postponed {
+		t.Error("a failed reprice was postponed, want it retried as an attempt")
+	}
+	if len(fake.AdjustedProducts) != 0 {
+		t.Errorf("adjusted %v after features failed, want nothing", fake.AdjustedProducts)
+	}
+}
+
+func TestRepriceOnSalePostponesWhileCircuitOpen(t *testing.T) {
+	fake := pricing.NewFakeBackend()
+	fake.ComputeFeaturesErr = &pricing.UnavailableError{Err: errors.New("connection refused")}
+	handle := newSaleHandler(fake, pricing.BreakerConfig{Threshold: 1, Cooldown: time.Minute})
+
+	if err := handle(context.Background(), saleEvent(t, 1, 7)); err == nil {
+		t.Fatal("handler error = nil with the backend down, want an error")
+	}
+
+	err := handle(context.Background(), saleEvent(t, 2, 7))
+	wait, postponed := outbox.Postponed(err)
+	if !postponed || !errors.Is(err, pricing.ErrCircuitOpen) {
+		t.Fatalf("handler error = %v, want the open circuit postponed", err)
+	}
+	if wait <= 0 || wait > time.Minute {
+		t.Errorf("postponed for %s, want up to the breaker cooldown", wait)
+	}
+	if len(fake.ComputedProducts) != 1 {
+		t.Errorf("backend called %d times, want it left alone while the circuit is open", len(fake.ComputedProducts))
+	}
+}
@@ -20,8 +20,19 @@ func CustomerRoutes(e *echo.Echo, customerHandlers *CustomerHdl) {
     customer.GET("/product-by-name/:name", func(c echo.Context) error {
         return customerHandlers.ProductHandler.GetProductByName(c)
     })
+    customer.GET("/products/:id/price-explanation", func(c echo.Context) error {
+        return customerHandlers.ProductHandler.ExplainPrice(c)
+    })
+    customer.GET("/products/:id/price-history", func(c echo.Context) error {
+        return customerHandlers.ProductHandler.GetPriceHistory(c)
+    })
 
     protected := customer.Group("", CustomerAuthMiddleware())
+
+    // live events
+    protected.GET("/events", func(c echo.Context) error {
+        return customerHandlers.EventHandler.Stream(c)
+    })
     
     // cart
     protected.GET("/cart-with-items", func(c echo.Context) error{
@@ -44,6 +55,15 @@ func CustomerRoutes(e *echo.Echo, customerHandlers *CustomerHdl) {
     protected.GET("/order-with-items", func(c echo.Context) error {
         return customerHandlers.OrderHandler.GetOrderWithItems(c)
     })
+    protected.GET("/orders", func(c echo.Context) error {
+        return customerHandlers.OrderHandler.GetOrders(c)
+    })
+    protected.GET("/orders/:id", func(c echo.Context) error {
+        return customerHandlers.OrderHandler.GetOrder(c)
+    })
+    protected.POST("/orders/:id/cancel", func(c echo.Context) error {
+        return customerHandlers.OrderHandler.CancelOrder(c)
+    })
 
     // payment
     protected.POST("/payment", func(c echo.Context) error {
@@ -3,6 +3,7 @@ package app
 import (
 	"github.com/Daniel-Njaramba-1/pulse/internal/api/adminHdl"
 	"github.com/Daniel-Njaramba-1/pulse/internal/api/customerHdl"
+	"github.com/Daniel-Njaramba-1/pulse/internal/events"
 )
 
 type AdminHdl struct {
@@ -12,6 +13,16 @@ type AdminHdl struct {
 	ProductHandler *adminHdl.ProductHandler
 	DashboardHandler *adminHdl.DashboardHandler
 	CustomerHandler *adminHdl.CustomerHandler
+	PricingPolicyHandler *adminHdl.PricingPolicyHandler
+	PricingProposalHandler *adminHdl.PricingProposalHandler
+	ModelRegistryHandler *adminHdl.ModelRegistryHandler
+	PriceExperimentHandler *adminHdl.PriceExperimentHandler
+	PriceScheduleHandler *adminHdl.PriceScheduleHandler
+	RepricingJobHandler *adminHdl.RepricingJobHandler
+	WebhookHandler *adminHdl.WebhookHandler
+	JobHandler *adminHdl.JobHandler
+	OrderHandler *adminHdl.OrderHandler
+	EventHandler *adminHdl.EventHandler
 }
 
 type CustomerHdl struct {
@@ -22,9 +33,10 @@ type CustomerHdl struct {
 	PaymentHandler *customerHdl.PaymentHandler
 	ReviewHandler *customerHdl.ReviewHandler
 	WishlistHandler *customerHdl.WishlistHandler
+	EventHandler *customerHdl.EventHandler
 }
 
-func NewAdminHdl(adminSvc *AdminServices) *AdminHdl {
+func NewAdminHdl(adminSvc *AdminServices, hub *events.Hub) *AdminHdl {
 	return &AdminHdl{
 		AuthHandler: adminHdl.NewAuthHandler(adminSvc.authentication),
 		BrandHandler: adminHdl.NewBrandHandler(adminSvc.brandService),
@@ -32,10 +44,20 @@ func NewAdminHdl(adminSvc *AdminServices) *AdminHdl {
 		ProductHandler: adminHdl.NewProductHandler(adminSvc.productService),
 		DashboardHandler: adminHdl.NewDashboardHandler(adminSvc.dashboardService),
 		CustomerHandler: adminHdl.NewCustomerHandler(*adminSvc.customerService),
+		PricingPolicyHandler: adminHdl.NewPricingPolicyHandler(adminSvc.pricingPolicyService),
+		PricingProposalHandler: adminHdl.NewPricingProposalHandler(adminSvc.pricingProposalService),
+		ModelRegistryHandler: adminHdl.NewModelRegistryHandler(adminSvc.modelRegistryService),
+		PriceExperimentHandler: adminHdl.NewPriceExperimentHandler(adminSvc.priceExperimentService),
+		PriceScheduleHandler: adminHdl.NewPriceScheduleHandler(adminSvc.priceScheduleService),
+		RepricingJobHandler: adminHdl.NewRepricingJobHandler(adminSvc.repricingJobService),
+		WebhookHandler: adminHdl.NewWebhookHandler(adminSvc.webhookService),
+		JobHandler: adminHdl.NewJobHandler(adminSvc.jobService),
+		OrderHandler: adminHdl.NewOrderHandler(adminSvc.orderService),
+		EventHandler: adminHdl.NewEventHandler(hub),
 	}
 }
 
-func NewCustomerHdl(customerSvc *CustomerServices) *CustomerHdl {
+func NewCustomerHdl(customerSvc *CustomerServices, hub *events.Hub, allowedOrigins []string) *CustomerHdl {
 	return &CustomerHdl{
 		AuthHandler: customerHdl.NewAuthHandler(customerSvc.authentication),
 		ProductHandler: customerHdl.NewProductHandler(customerSvc.productService),
@@ -44,5 +66,6 @@ func NewCustomerHdl(customerSvc *CustomerServices) *CustomerHdl {
 		PaymentHandler: customerHdl.NewPaymentHandler(customerSvc.paymentService),
 		ReviewHandler: customerHdl.NewReviewHandler(customerSvc.reviewService),
 		WishlistHandler: customerHdl.NewWishlistHandler(customerSvc.wishlistService),
+		EventHandler: customerHdl.NewEventHandler(hub, allowedOrigins),
 	}
 }
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Lifecycle runs the background workers under one context, so that they are stopped together
+type Lifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewLifecycle() *Lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Lifecycle{ctx: ctx, cancel: cancel}
+}
+
+// Go starts a worker, which must return once its context is cancelled
+func (l *Lifecycle) Go(name string, run func(ctx context.Context)) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		run(l.ctx)
+		log.Printf("Stopped %s", name)
+	}()
+}
+
+// Stop cancels every worker and waits for them to return, giving up when ctx is done
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.cancel()
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("background workers did not stop in time: %w", ctx.Err())
+	}
+}
@@ -1,6 +1,9 @@
 package app
 
 import (
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/scheduler"
 	"github.com/Daniel-Njaramba-1/pulse/internal/services/adminSvc"
 	"github.com/Daniel-Njaramba-1/pulse/internal/services/customerSvc"
 	"github.com/jmoiron/sqlx"
@@ -13,6 +16,15 @@ type AdminServices struct {
 	productService *adminSvc.ProductService
 	dashboardService *adminSvc.DashboardService
 	customerService *adminSvc.CustomerService
+	pricingPolicyService *adminSvc.PricingPolicyService
+	pricingProposalService *adminSvc.PricingProposalService
+	modelRegistryService *adminSvc.ModelRegistryService
+	priceExperimentService *adminSvc.PriceExperimentService
+	priceScheduleService *adminSvc.PriceScheduleService
+	repricingJobService *adminSvc.RepricingJobService
+	webhookService *adminSvc.WebhookService
+	jobService *adminSvc.JobService
+	orderService *adminSvc.OrderService
 }
 
 type CustomerServices struct {
@@ -25,13 +37,25 @@ type CustomerServices struct {
 	wishlistService *customerSvc.WishlistService
 }
 
-func NewAdminServices(db *sqlx.DB) *AdminServices {
+func NewAdminServices(db *sqlx.DB, repricer *pricing.Repricer, jobScheduler *scheduler.Scheduler) *AdminServices {
 	authentication := adminSvc.NewAuthentication(db)
 	brandService := adminSvc.NewBrandService(db)
 	categoryService := adminSvc.NewCategoryService(db)
-	productService := adminSvc.NewProductService(db, categoryService, brandService)
-	dashboardService := adminSvc.NewDashboardService(db)
+	guard := pricing.NewGuard(db)
+	productService := adminSvc.NewProductService(db, categoryService, brandService, guard)
+	registry := pricing.NewRegistry(db)
+	engine := pricing.NewEngine(db)
+	dashboardService := adminSvc.NewDashboardService(db, engine, registry, pricing.NewElasticityEstimator(db))
 	customerService := adminSvc.NewCustomerService(db)
+	pricingPolicyService := adminSvc.NewPricingPolicyService(db, guard)
+	pricingProposalService := adminSvc.NewPricingProposalService(db, pricing.NewProposalQueue(db, guard))
+	modelRegistryService := adminSvc.NewModelRegistryService(registry, engine)
+	priceExperimentService := adminSvc.NewPriceExperimentService(db, registry)
+	priceScheduleService := adminSvc.NewPriceScheduleService(db, pricing.NewPriceSchedules(db, guard))
+	repricingJobService := adminSvc.NewRepricingJobService(outbox.NewStore(db, outbox.TopicSale), repricer)
+	webhookService := adminSvc.NewWebhookService(db)
+	jobService := adminSvc.NewJobService(jobScheduler)
+	orderService := adminSvc.NewOrderService(db)
 
 	return &AdminServices{
 		authentication: authentication,
@@ -40,15 +64,25 @@ func NewAdminServices(db *sqlx.DB) *AdminServices {
 		productService: productService,
 		dashboardService: dashboardService,
 		customerService: customerService,
+		pricingPolicyService: pricingPolicyService,
+		pricingProposalService: pricingProposalService,
+		modelRegistryService: modelRegistryService,
+		priceExperimentService: priceExperimentService,
+		priceScheduleService: priceScheduleService,
+		repricingJobService: repricingJobService,
+		webhookService: webhookService,
+		jobService: jobService,
+		orderService: orderService,
 	}
 }
 
 func NewCustomerServices(db *sqlx.DB) *CustomerServices {
 	authentication := customerSvc.NewAuthentication(db)
-	productService := customerSvc.NewProductService(db)
-	cartService := customerSvc.NewCartService(db)
-	orderService := customerSvc.NewOrderService(db)
-	paymentService := customerSvc.NewPaymentService(db)
+	productService := customerSvc.NewProductService(db, pricing.NewEngine(db))
+	experiments := pricing.NewExperiments(db)
+	cartService := customerSvc.NewCartService(db, experiments)
+	orderService := customerSvc.NewOrderService(db, experiments)
+	paymentService := customerSvc.NewPaymentService(db, experiments)
 	reviewService := customerSvc.NewReviewService(db)
 	wishlistService := customerSvc.NewWishlistService(db)
 
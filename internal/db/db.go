@@ -1,18 +1,15 @@
 package db
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
-	"sync"
 	"time"
 
 	"github.com/Daniel-Njaramba-1/pulse/internal/config"
-	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/Daniel-Njaramba-1/pulse/internal/events"
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
@@ -42,69 +39,6 @@ func BuildConnStr(cfg *DBConfig) string {
 		cfg.Host, cfg.User, cfg.Password, cfg.Dbname)
 }
 
-// ClientManager handles SSE client connections
-type ClientManager struct {
-	clients    map[chan string]bool
-	register   chan chan string
-	unregister chan chan string
-	broadcast  chan string
-	mutex      sync.Mutex
-}
-
-// NewClientManager creates a new SSE client manager
-func NewClientManager() *ClientManager {
-	return &ClientManager{
-		clients:    make(map[chan string]bool),
-		register:   make(chan chan string),
-		unregister: make(chan chan string),
-		broadcast:  make(chan string),
-		mutex:      sync.Mutex{},
-	}
-}
-
-// Global manager instance
-var Manager = NewClientManager()
-
-// Run starts the client manager's main loop
-func (m *ClientManager) Run() {
-	for {
-		select {
-		case client := <-m.register:
-			m.mutex.Lock()
-			m.clients[client] = true
-			m.mutex.Unlock()
-			log.Println("Registered SSE client")
-		case client := <-m.unregister:
-			m.mutex.Lock()
-			if _, ok := m.clients[client]; ok {
-				delete(m.clients, client)
-				close(client)
-			}
-			m.mutex.Unlock()
-			log.Println("Unregistered SSE client")
-		case msg := <-m.broadcast:
-			m.mutex.Lock()
-			for client := range m.clients {
-				select {
-				case client <- msg:
-				default:
-					close(client)
-					delete(m.clients, client)
-				}
-			}
-			m.mutex.Unlock()
-		}
-	}
-}
-
-func (m *ClientManager) RegisterChannel(ch chan string) {
-	m.register <- ch
-}
-
-func (m *ClientManager) UnregisterChannel(ch chan string) {
-	m.unregister <- ch
-}
-
 // InitDB initializes the database connection using the provided configuration
 func InitDB(ctx context.Context, cfg *DBConfig) (*sqlx.DB, error) {
 	connStr := BuildConnStr(cfg)
@@ -137,103 +71,9 @@ func ConnDB(ctx context.Context) (*sqlx.DB, error) {
 	return db, nil
 }
 
-// StartSaleListener adjusts price after a sale notification
-func StartSaleListener(connStr string) {
-	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
-		if err != nil {
-			log.Printf("Error in sales listener event: %v", err)
-			return
-		}
-	})
-	defer listener.Close()
-
-	err := listener.Listen("sale") // Listen on 'sale' channel
-	if err != nil {
-		log.Printf("Error setting up sales listener: %v", err)
-		return
-	}
-	log.Println("Listening for sales")
-
-	for {
-		select {
-		case n := <-listener.Notify:
-			if n == nil {
-				continue
-			}
-			logging.LogInfo("Received sale notification: %v", n.Extra)
-			log.Printf("Received sale notification: %v", n.Extra)
-
-			var sale struct {
-				ID        int     `json:"id"`
-				ProductID int     `json:"product_id"`
-				SalePrice float64 `json:"sale_price"`
-				Quantity  int     `json:"quantity"`
-				CreatedAt string  `json:"created_at"`
-			}
-
-			// Use n.Extra which contains the payload from the trigger
-			if err := json.Unmarshal([]byte(n.Extra), &sale); err != nil {
-				log.Printf("Error parsing sale notification: %v", err)
-				continue
-			}
-
-			// Call compute features API
-			apiURL := "http://localhost:5872/compute_features"
-			payload := map[string]int{
-				"product_id": sale.ProductID,
-			}
-			jsonData, err := json.Marshal(payload)
-			if err != nil {
-				logging.LogError("Error marshaling request data: %v", err)
-				log.Printf("Error marshaling request data: %v", err)
-				continue
-			}
-
-			resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(jsonData))
-			if err != nil {
-				logging.LogError("Error calling compute features API: %v", err)
-				log.Printf("Error calling compute features API: %v", err)
-				continue
-			}
-
-			if resp.StatusCode != http.StatusOK {
-				logging.LogError("Error response from compute features API: %v", resp.Status)
-				log.Printf("Error response from compute features API: %v", resp.Status)
-				continue
-			}
-
-			log.Printf("Successfully triggered feature computation for product %d", sale.ProductID)
-			logging.LogInfo("Successfully triggered feature computation for product %d", sale.ProductID)
-
-			// Call adjust price API for the specific product
-			adjustURL := fmt.Sprintf("http://localhost:5872/adjust-price/%d", sale.ProductID)
-			adjustResp, err := http.Post(adjustURL, "application/json", nil)
-			if err != nil {
-				logging.LogError("Error calling adjust price API: %v", err)
-				log.Printf("Error calling adjust price API: %v", err)
-				continue
-			}
-			if adjustResp.StatusCode != http.StatusOK {
-				logging.LogError("Error response from adjust price API: %v", adjustResp.Status)
-				log.Printf("Error response from adjust price API: %v", adjustResp.Status)
-				continue
-			}
-			log.Printf("Successfully triggered price adjustment for product %d", sale.ProductID)
-			logging.LogInfo("Successfully triggered price adjustment for product %d", sale.ProductID)
-
-		case <-time.After(90 * time.Second):
-			// Ping the listener to keep the connection alive
-			go func() {
-				if err := listener.Ping(); err != nil {
-					log.Printf("Error pinging sales listener: %v", err)
-				}
-			}()
-		}
-	}
-}
-
-// StartPriceAdjustmentListener initializes the PostgreSQL notification listener
-func StartPriceAdjustmentListener(connStr string) {
+// StartPriceAdjustmentListener initializes the PostgreSQL notification listener.
+// Each adjustment is published to the hub as a price change, until ctx is cancelled.
+func StartPriceAdjustmentListener(ctx context.Context, connStr string, hub *events.Hub) {
 	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
 		if err != nil {
 			log.Printf("Error in price adjustments listener event: %v", err)
@@ -259,6 +99,8 @@ func StartPriceAdjustmentListener(connStr string) {
 
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case n := <-listener.Notify:
 			if n == nil {
 				continue
@@ -273,6 +115,8 @@ func StartPriceAdjustmentListener(connStr string) {
 				ProductID int     `json:"product_id"`
 				OldPrice  float64 `json:"old_price"`
 				NewPrice  float64 `json:"new_price"`
+				Source    *string `json:"source"`
+				PriceType string  `json:"price_type"`
 				CreatedAt string  `json:"created_at"`
 			}
 
@@ -289,16 +133,21 @@ func StartPriceAdjustmentListener(connStr string) {
 				PriceChange float64 `json:"price_change"`
 				ChangeType  string  `json:"change_type"`
 				ProductName string  `json:"product_name"`
+				Source      *string `json:"source"`
+				PriceType   string  `json:"price_type"`
 			}{
 				ProductID:   adjustment.ProductID,
 				NewPrice:    adjustment.NewPrice,
 				ChangedAt:   adjustment.CreatedAt,
 				PriceChange: adjustment.NewPrice - adjustment.OldPrice,
 				ChangeType:  getChangeType(adjustment.NewPrice, adjustment.OldPrice),
+				Source:      adjustment.Source,
+				PriceType:   adjustment.PriceType,
 			}
 
-			// Get product name
-			err := db.QueryRow("SELECT name FROM products WHERE id = $1", adjustment.ProductID).Scan(&optimizedData.ProductName)
+			// Get product name and category
+			var categoryID int
+			err := db.QueryRow("SELECT name, category_id FROM products WHERE id = $1", adjustment.ProductID).Scan(&optimizedData.ProductName, &categoryID)
 			if err != nil {
 				log.Printf("Error fetching product name: %v", err)
 				optimizedData.ProductName = fmt.Sprintf("Product #%d", adjustment.ProductID)
@@ -311,8 +160,14 @@ func StartPriceAdjustmentListener(connStr string) {
 				continue
 			}
 
-			// Broadcast the optimized data
-			Manager.broadcast <- string(optimizedJSON)
+			// Publish the optimized data
+			hub.Publish(&events.Event{
+				Topic:      events.TopicPriceChange,
+				ProductId:  adjustment.ProductID,
+				CategoryId: categoryID,
+				Data:       optimizedJSON,
+			})
+			publishCartPriceChanges(db, hub, adjustment.ProductID, optimizedJSON)
 
 		case <-time.After(90 * time.Second):
 			go func() {
@@ -324,6 +179,130 @@ func StartPriceAdjustmentListener(connStr string) {
 	}
 }
 
+// StartEventListener publishes stock and order notifications to the hub until ctx is cancelled.
+// A stock change is also a restock when the quantity went up, and a low-stock alert when it
+// fell to the product's threshold.
+func StartEventListener(ctx context.Context, connStr string, hub *events.Hub) {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Error in event listener event: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	for _, channel := range []string{"stock_change", "order_status"} {
+		if err := listener.Listen(channel); err != nil {
+			log.Printf("Error setting up %s listener: %v", channel, err)
+			return
+		}
+	}
+	log.Println("Listening for stock and order events")
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		log.Printf("Error opening DB connection for event processing: %v", err)
+		return
+	}
+	defer db.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-listener.Notify:
+			if n == nil {
+				continue
+			}
+			switch n.Channel {
+			case "stock_change":
+				publishStockChange(db, hub, n.Extra)
+			case "order_status":
+				publishOrderStatus(hub, n.Extra)
+			}
+
+		case <-time.After(90 * time.Second):
+			go func() {
+				if err := listener.Ping(); err != nil {
+					log.Printf("Error pinging event listener: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+func publishStockChange(db *sql.DB, hub *events.Hub, payload string) {
+	var stock struct {
+		ProductID      int `json:"product_id"`
+		OldQuantity    int `json:"old_quantity"`
+		Quantity       int `json:"quantity"`
+		StockThreshold int `json:"stock_threshold"`
+	}
+	if err := json.Unmarshal([]byte(payload), &stock); err != nil {
+		log.Printf("Error parsing stock notification: %v", err)
+		return
+	}
+
+	var categoryID int
+	if err := db.QueryRow("SELECT category_id FROM products WHERE id = $1", stock.ProductID).Scan(&categoryID); err != nil {
+		log.Printf("Error fetching product category: %v", err)
+	}
+	event := func(topic events.Topic) *events.Event {
+		return &events.Event{Topic: topic, ProductId: stock.ProductID, CategoryId: categoryID, Data: json.RawMessage(payload)}
+	}
+
+	hub.Publish(event(events.TopicStockChange))
+	if stock.Quantity > stock.OldQuantity {
+		hub.Publish(event(events.TopicRestock))
+	}
+	if stock.Quantity <= stock.StockThreshold && stock.OldQuantity > stock.StockThreshold {
+		hub.Publish(event(events.TopicLowStock))
+	}
+}
+
+// publishCartPriceChanges tells every customer with the product in their cart about its new price
+func publishCartPriceChanges(db *sql.DB, hub *events.Hub, productID int, priceChange json.RawMessage) {
+	rows, err := db.Query(`
+		SELECT c.customer_id, ci.quantity
+		FROM cart_items ci
+		JOIN carts c ON c.id = ci.cart_id
+		WHERE ci.product_id = $1 AND ci.is_processed = FALSE AND c.is_active = TRUE
+	`, productID)
+	if err != nil {
+		log.Printf("Error fetching carts with product %d: %v", productID, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var customerID, quantity int
+		if err := rows.Scan(&customerID, &quantity); err != nil {
+			log.Printf("Error reading cart item: %v", err)
+			return
+		}
+		data := struct {
+			Quantity    int             `json:"quantity"`
+			PriceChange json.RawMessage `json:"price_change"`
+		}{quantity, priceChange}
+		if err := hub.PublishData(&events.Event{Topic: events.TopicCartPrice, ProductId: productID, CustomerId: customerID}, data); err != nil {
+			log.Printf("Error publishing cart price change: %v", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Error fetching carts with product %d: %v", productID, err)
+	}
+}
+
+func publishOrderStatus(hub *events.Hub, payload string) {
+	var order struct {
+		CustomerID int `json:"customer_id"`
+	}
+	if err := json.Unmarshal([]byte(payload), &order); err != nil {
+		log.Printf("Error parsing order notification: %v", err)
+		return
+	}
+	hub.Publish(&events.Event{Topic: events.TopicOrderStatus, CustomerId: order.CustomerID, Data: json.RawMessage(payload)})
+}
+
 // Helper function to determine price change type
 func getChangeType(newPrice, oldPrice float64) string {
 	if newPrice > oldPrice {
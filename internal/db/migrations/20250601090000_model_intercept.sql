@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The in-process pricing engine fits an intercept alongside the feature coefficients.
+-- Existing rows default to 0 so they predict exactly as before.
+ALTER TABLE price_model_coefficients
+    ADD COLUMN IF NOT EXISTS intercept DECIMAL(12, 6) NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE price_model_coefficients
+    DROP COLUMN IF EXISTS intercept;
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Unit cost of a product, used by the minimum margin guardrail
+ALTER TABLE product_metrics
+    ADD COLUMN IF NOT EXISTS cost_price DECIMAL(10, 2) CHECK (cost_price >= 0);
+
+-- Price guardrails for a product, a category or a brand.
+-- When several apply, each limit is taken from the most specific policy that sets it (product > category > brand).
+CREATE TABLE IF NOT EXISTS pricing_policies (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('product', 'category', 'brand')),
+    scope_id INTEGER NOT NULL,
+    floor_price DECIMAL(10, 2) CHECK (floor_price >= 0),
+    ceiling_price DECIMAL(10, 2) CHECK (ceiling_price >= 0),
+    max_change_pct DECIMAL(6, 2) CHECK (max_change_pct >= 0), -- max change per adjustment, in percent
+    max_daily_change_pct DECIMAL(6, 2) CHECK (max_daily_change_pct >= 0), -- max change from the day's opening price, in percent
+    min_margin_pct DECIMAL(6, 2) CHECK (min_margin_pct >= 0), -- minimum margin over cost_price, in percent
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (scope, scope_id),
+    CHECK (floor_price IS NULL OR ceiling_price IS NULL OR floor_price <= ceiling_price)
+);
+
+-- Price changes blocked by a pricing policy
+CREATE TABLE IF NOT EXISTS price_adjustment_rejections (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    price_type VARCHAR(20) NOT NULL, -- adjusted, base
+    old_price DECIMAL(10, 2) NOT NULL,
+    proposed_price DECIMAL(10, 2) NOT NULL,
+    source VARCHAR(50) NOT NULL, -- sale, scheduled, manual
+    model_version VARCHAR(50),
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_adjustment_rejections_product_id ON price_adjustment_rejections(product_id);
+CREATE INDEX IF NOT EXISTS idx_price_adjustments_product_id_created_at ON price_adjustments(product_id, created_at);
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON pricing_policies
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_adjustment_rejections
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_price_adjustments_product_id_created_at;
+DROP INDEX IF EXISTS idx_price_adjustment_rejections_product_id;
+DROP TABLE IF EXISTS price_adjustment_rejections;
+DROP TABLE IF EXISTS pricing_policies;
+ALTER TABLE product_metrics DROP COLUMN IF EXISTS cost_price;
+-- +goose StatementEnd
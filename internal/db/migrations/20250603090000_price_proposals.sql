@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Confidence of the model that produced an adjustment, between 0 and 1
+ALTER TABLE price_adjustments
+    ADD COLUMN IF NOT EXISTS confidence_score DECIMAL(5, 4) CHECK (confidence_score BETWEEN 0 AND 1);
+
+-- Adjustments waiting for review when pricing review mode is on.
+-- Only approved proposals change the live price.
+CREATE TABLE IF NOT EXISTS price_proposals (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    old_price DECIMAL(10, 2) NOT NULL CHECK (old_price >= 0), -- Adjusted price when the proposal was made
+    new_price DECIMAL(10, 2) NOT NULL CHECK (new_price >= 0),
+    change_pct DECIMAL(8, 2) NOT NULL,
+    model_version VARCHAR(50) NOT NULL,
+    confidence_score DECIMAL(5, 4) CHECK (confidence_score BETWEEN 0 AND 1),
+    source VARCHAR(50) NOT NULL, -- sale, scheduled
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'auto_approved', 'rejected', 'superseded')),
+    reviewed_by VARCHAR(255),
+    review_note TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+-- A product has at most one pending proposal; a newer one supersedes it
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_proposals_pending_product_id ON price_proposals(product_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_price_proposals_status_created_at ON price_proposals(status, created_at);
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_proposals
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_price_proposals_status_created_at;
+DROP INDEX IF EXISTS idx_price_proposals_pending_product_id;
+DROP TABLE IF EXISTS price_proposals;
+ALTER TABLE price_adjustments DROP COLUMN IF EXISTS confidence_score;
+-- +goose StatementEnd
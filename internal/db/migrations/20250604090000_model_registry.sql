@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Registry state of each trained model.
+-- candidate: trained, not serving; active: sets prices; shadow: priced next to the active model without changing prices; retired: no longer used
+ALTER TABLE price_model_coefficients
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'candidate' CHECK (status IN ('candidate', 'active', 'shadow', 'retired')),
+    ADD COLUMN IF NOT EXISTS promoted_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS retired_at TIMESTAMP;
+
+-- The newest existing model keeps serving, the rest are retired
+UPDATE price_model_coefficients
+SET status = 'retired', retired_at = CURRENT_TIMESTAMP;
+
+UPDATE price_model_coefficients
+SET status = 'active', promoted_at = training_date, retired_at = NULL
+WHERE id = (SELECT id FROM price_model_coefficients ORDER BY training_date DESC LIMIT 1);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_model_coefficients_model_version ON price_model_coefficients(model_version);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_model_coefficients_active ON price_model_coefficients(status) WHERE status = 'active';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_price_model_coefficients_shadow ON price_model_coefficients(status) WHERE status = 'shadow';
+
+-- Prices the shadow model would have set, next to the prices set by the active model
+CREATE TABLE IF NOT EXISTS model_shadow_predictions (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    active_model_version VARCHAR(50) NOT NULL,
+    shadow_model_version VARCHAR(50) NOT NULL,
+    active_price DECIMAL(10, 2) NOT NULL,
+    shadow_price DECIMAL(10, 2) NOT NULL,
+    price_diff DECIMAL(10, 2) NOT NULL, -- shadow_price - active_price
+    source VARCHAR(50) NOT NULL, -- sale, scheduled
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_shadow_predictions_shadow_model_version ON model_shadow_predictions(shadow_model_version, created_at);
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON model_shadow_predictions
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_model_shadow_predictions_shadow_model_version;
+DROP TABLE IF EXISTS model_shadow_predictions;
+DROP INDEX IF EXISTS idx_price_model_coefficients_shadow;
+DROP INDEX IF EXISTS idx_price_model_coefficients_active;
+DROP INDEX IF EXISTS idx_price_model_coefficients_model_version;
+ALTER TABLE price_model_coefficients
+    DROP COLUMN IF EXISTS retired_at,
+    DROP COLUMN IF EXISTS promoted_at,
+    DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
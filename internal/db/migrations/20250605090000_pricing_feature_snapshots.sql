@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Append-only history of pricing features, one row per recomputation.
+-- Prices are captured alongside so a training set can be rebuilt for any time window.
+CREATE TABLE IF NOT EXISTS pricing_feature_snapshots (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    days_since_last_sale INTEGER,
+    sales_velocity DECIMAL(10, 2) DEFAULT 0,
+    total_sales_count INTEGER DEFAULT 0,
+    total_sales_value INTEGER DEFAULT 0,
+    category_percentile DECIMAL(10, 2),
+    review_score DECIMAL(10, 2) DEFAULT 0,
+    wishlist_to_sales_ratio DECIMAL (10, 2) DEFAULT 0,
+    days_since_restock INTEGER DEFAULT 0,
+    base_price DECIMAL(10, 2),
+    adjusted_price DECIMAL(10, 2),
+    captured_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_pricing_feature_snapshots_captured_at ON pricing_feature_snapshots(captured_at);
+CREATE INDEX IF NOT EXISTS idx_pricing_feature_snapshots_product_id_captured_at ON pricing_feature_snapshots(product_id, captured_at);
+
+-- Seed the history with the current features
+INSERT INTO pricing_feature_snapshots (
+    product_id, days_since_last_sale, sales_velocity, total_sales_count, total_sales_value,
+    category_percentile, review_score, wishlist_to_sales_ratio, days_since_restock,
+    base_price, adjusted_price, captured_at
+)
+SELECT
+    pf.product_id, pf.days_since_last_sale, pf.sales_velocity, pf.total_sales_count, pf.total_sales_value,
+    pf.category_percentile, pf.review_score, pf.wishlist_to_sales_ratio, pf.days_since_restock,
+    pm.base_price, pm.adjusted_price, COALESCE(pf.updated_at, CURRENT_TIMESTAMP)
+FROM pricing_features pf
+JOIN product_metrics pm ON pm.product_id = pf.product_id;
+
+-- The snapshot window each model was trained on
+ALTER TABLE price_model_coefficients
+    ADD COLUMN IF NOT EXISTS training_window_start TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS training_window_end TIMESTAMP;
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON pricing_feature_snapshots
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE price_model_coefficients
+    DROP COLUMN IF EXISTS training_window_end,
+    DROP COLUMN IF EXISTS training_window_start;
+DROP INDEX IF EXISTS idx_pricing_feature_snapshots_product_id_captured_at;
+DROP INDEX IF EXISTS idx_pricing_feature_snapshots_captured_at;
+DROP TABLE IF EXISTS pricing_feature_snapshots;
+-- +goose StatementEnd
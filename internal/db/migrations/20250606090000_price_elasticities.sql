@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Price elasticity of demand estimated from the price history and sales.
+-- Products with too little price variation have no product row and fall back to their category's.
+CREATE TABLE IF NOT EXISTS price_elasticities (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('product', 'category')),
+    scope_id INTEGER NOT NULL,
+    elasticity DECIMAL(10, 4) NOT NULL,
+    r_squared DECIMAL(10, 4),
+    observations INTEGER NOT NULL DEFAULT 0,
+    window_days INTEGER NOT NULL,
+    estimated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (scope, scope_id)
+);
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_elasticities
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS price_elasticities;
+-- +goose StatementEnd
@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A/B price experiments. Customers are bucketed into control or treatment per experiment;
+-- the treatment arm sees the live price scaled by price_multiplier, or the price from model_version.
+CREATE TABLE IF NOT EXISTS price_experiments (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    variant_type VARCHAR(20) NOT NULL CHECK (variant_type IN ('multiplier', 'model')),
+    price_multiplier DECIMAL(10, 4),
+    model_version VARCHAR(50) REFERENCES price_model_coefficients(model_version),
+    treatment_pct DECIMAL(5, 2) NOT NULL CHECK (treatment_pct > 0 AND treatment_pct < 100),
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'stopped')),
+    created_by VARCHAR(100),
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    stopped_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (
+        (variant_type = 'multiplier' AND price_multiplier > 0)
+        OR (variant_type = 'model' AND model_version IS NOT NULL)
+    )
+);
+
+CREATE TABLE IF NOT EXISTS price_experiment_products (
+    id SERIAL PRIMARY KEY,
+    experiment_id INTEGER NOT NULL,
+    product_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (experiment_id, product_id),
+    FOREIGN KEY (experiment_id) REFERENCES price_experiments(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_experiment_products_product_id ON price_experiment_products(product_id);
+
+-- The first time each customer was shown an experiment price
+CREATE TABLE IF NOT EXISTS price_experiment_exposures (
+    id SERIAL PRIMARY KEY,
+    experiment_id INTEGER NOT NULL,
+    customer_id INTEGER NOT NULL,
+    arm VARCHAR(20) NOT NULL CHECK (arm IN ('control', 'treatment')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (experiment_id, customer_id),
+    FOREIGN KEY (experiment_id) REFERENCES price_experiments(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE
+);
+
+-- Attribute ordered items to the arm that priced them
+ALTER TABLE order_items
+    ADD COLUMN IF NOT EXISTS experiment_id INTEGER REFERENCES price_experiments(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS experiment_arm VARCHAR(20);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_experiment_id ON order_items(experiment_id);
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_experiments
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_experiment_products
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_experiment_exposures
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_items_experiment_id;
+ALTER TABLE order_items
+    DROP COLUMN IF EXISTS experiment_arm,
+    DROP COLUMN IF EXISTS experiment_id;
+DROP TABLE IF EXISTS price_experiment_exposures;
+DROP INDEX IF EXISTS idx_price_experiment_products_product_id;
+DROP TABLE IF EXISTS price_experiment_products;
+DROP TABLE IF EXISTS price_experiments;
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Base or adjusted price changes that take effect at starts_at.
+-- When ends_at is set the change is temporary and the price in place before it is restored at ends_at.
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL,
+    price_type VARCHAR(20) NOT NULL CHECK (price_type IN ('base', 'adjusted')),
+    price DECIMAL(10, 2) NOT NULL CHECK (price > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP CHECK (ends_at > starts_at),
+    revert_price DECIMAL(10, 2),
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled', -- scheduled, active, completed, cancelled, failed
+    note TEXT,
+    failure_reason TEXT,
+    created_by VARCHAR(100),
+    applied_at TIMESTAMP,
+    reverted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_schedules_status_starts_at ON price_schedules(status, starts_at);
+CREATE INDEX IF NOT EXISTS idx_price_schedules_status_ends_at ON price_schedules(status, ends_at);
+CREATE INDEX IF NOT EXISTS idx_price_schedules_product_id ON price_schedules(product_id);
+
+-- Record what changed a price and which price it was.
+-- Model adjustments made before this migration have no source.
+ALTER TABLE price_adjustments
+    ADD COLUMN IF NOT EXISTS source VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS price_type VARCHAR(20) NOT NULL DEFAULT 'adjusted' CHECK (price_type IN ('base', 'adjusted')),
+    ADD COLUMN IF NOT EXISTS schedule_id INTEGER REFERENCES price_schedules(id) ON DELETE SET NULL,
+    ALTER COLUMN model_version DROP NOT NULL;
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON price_schedules
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM price_adjustments WHERE model_version IS NULL;
+ALTER TABLE price_adjustments
+    ALTER COLUMN model_version SET NOT NULL,
+    DROP COLUMN IF EXISTS schedule_id,
+    DROP COLUMN IF EXISTS price_type,
+    DROP COLUMN IF EXISTS source;
+DROP INDEX IF EXISTS idx_price_schedules_product_id;
+DROP INDEX IF EXISTS idx_price_schedules_status_ends_at;
+DROP INDEX IF EXISTS idx_price_schedules_status_starts_at;
+DROP TABLE IF EXISTS price_schedules;
+-- +goose StatementEnd
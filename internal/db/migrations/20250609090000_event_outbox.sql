@@ -0,0 +1,63 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Events written in the same transaction as the change they describe and dispatched afterwards.
+-- A row stays pending until a handler succeeds; failed attempts are retried at available_at.
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id SERIAL PRIMARY KEY,
+    topic VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, done, failed, discarded
+    attempts INTEGER NOT NULL DEFAULT 0,
+    available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    processed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_pending ON event_outbox(available_at) WHERE status = 'pending';
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON event_outbox
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- A new event wakes the dispatcher; the event itself is read from the table
+CREATE OR REPLACE FUNCTION notify_event_outbox()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('event_outbox', NEW.topic);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_event_outbox
+AFTER INSERT ON event_outbox
+FOR EACH ROW
+EXECUTE FUNCTION notify_event_outbox();
+
+-- Sales are announced through the outbox now
+DROP TRIGGER IF EXISTS trigger_notify_sale ON sales;
+DROP FUNCTION IF EXISTS notify_sale();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_sale()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('sale', row_to_json(NEW)::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_sale
+AFTER INSERT OR UPDATE ON sales
+FOR EACH ROW
+EXECUTE FUNCTION notify_sale();
+
+DROP TRIGGER IF EXISTS trigger_notify_event_outbox ON event_outbox;
+DROP FUNCTION IF EXISTS notify_event_outbox();
+DROP INDEX IF EXISTS idx_event_outbox_pending;
+DROP TABLE IF EXISTS event_outbox;
+-- +goose StatementEnd
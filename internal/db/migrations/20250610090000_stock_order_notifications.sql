@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Announce stock level changes for the live event streams
+CREATE OR REPLACE FUNCTION notify_stock_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('stock_change', json_build_object(
+        'product_id', NEW.product_id,
+        'old_quantity', OLD.quantity,
+        'quantity', NEW.quantity,
+        'stock_threshold', NEW.stock_threshold
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_stock_change
+AFTER UPDATE ON stocks
+FOR EACH ROW
+WHEN (OLD.quantity IS DISTINCT FROM NEW.quantity)
+EXECUTE FUNCTION notify_stock_change();
+
+-- Announce new orders and order status changes for the live event streams
+CREATE OR REPLACE FUNCTION notify_order_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.status IS NOT DISTINCT FROM NEW.status THEN
+        RETURN NEW;
+    END IF;
+    PERFORM pg_notify('order_status', json_build_object(
+        'order_id', NEW.id,
+        'customer_id', NEW.customer_id,
+        'old_status', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        'status', NEW.status,
+        'total_price', NEW.total_price
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_notify_order_status
+AFTER INSERT OR UPDATE ON orders
+FOR EACH ROW
+EXECUTE FUNCTION notify_order_status();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS trigger_notify_order_status ON orders;
+DROP FUNCTION IF EXISTS notify_order_status();
+DROP TRIGGER IF EXISTS trigger_notify_stock_change ON stocks;
+DROP FUNCTION IF EXISTS notify_stock_change();
+-- +goose StatementEnd
@@ -0,0 +1,58 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Background jobs and when they run. Jobs are defined in code and added here the first time
+-- the server starts; the schedule and pause state are then managed from the admin.
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    cron_expression VARCHAR(100) NOT NULL,
+    is_paused BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    last_status VARCHAR(20),
+    updated_by VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every run of a job, scheduled or triggered by hand. Manual runs wait as queued until the
+-- instance running the jobs picks them up.
+CREATE TABLE IF NOT EXISTS job_runs (
+    id SERIAL PRIMARY KEY,
+    job_id INTEGER NOT NULL,
+    trigger VARCHAR(20) NOT NULL, -- schedule, manual
+    status VARCHAR(20) NOT NULL, -- queued, running, succeeded, failed
+    requested_by VARCHAR(100),
+    instance VARCHAR(255),
+    result TEXT,
+    error TEXT,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    duration_ms BIGINT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (job_id) REFERENCES scheduled_jobs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs(job_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_queued ON job_runs(id) WHERE status = 'queued';
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON scheduled_jobs
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON job_runs
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_job_runs_queued;
+DROP INDEX IF EXISTS idx_job_runs_job_id;
+DROP TABLE IF EXISTS job_runs;
+DROP TABLE IF EXISTS scheduled_jobs;
+-- +goose StatementEnd
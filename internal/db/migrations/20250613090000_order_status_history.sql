@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Paid orders used to be marked completed; they are paid until fulfilment moves them on
+UPDATE orders SET status = 'paid' WHERE status = 'completed';
+
+ALTER TABLE orders ADD CONSTRAINT orders_status_check CHECK (status IN (
+    'pending', 'paid', 'processing', 'shipped', 'delivered', 'cancelled', 'refunded', 'failed'
+));
+
+-- Every change of an order's status, who made it and why. from_status is NULL for the
+-- order being placed.
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    actor_type VARCHAR(20) NOT NULL, -- customer, admin, system
+    actor VARCHAR(100),
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history(order_id, id);
+
+CREATE TRIGGER trigger_update_timestamp
+BEFORE UPDATE ON order_status_history
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Existing orders start their history at the status they have now
+INSERT INTO order_status_history (order_id, from_status, to_status, actor_type, reason, created_at)
+SELECT id, NULL, status, 'system', 'recorded before status history', updated_at
+FROM orders;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_order_status_history_order_id;
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_status_check;
+
+UPDATE orders SET status = 'completed' WHERE status IN ('paid', 'processing', 'shipped', 'delivered');
+UPDATE orders SET status = 'cancelled' WHERE status = 'refunded';
+-- +goose StatementEnd
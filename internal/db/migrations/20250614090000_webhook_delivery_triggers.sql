@@ -0,0 +1,150 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Numbers the events sent to webhooks; every delivery of one event carries the same id
+CREATE SEQUENCE IF NOT EXISTS webhook_event_id_seq;
+
+-- Records a delivery of an event for every active webhook subscribed to its type and queues
+-- each one in the outbox. Called from the triggers below, so the deliveries commit if and only
+-- if the change the event describes does.
+CREATE OR REPLACE FUNCTION enqueue_webhook_deliveries(event_topic TEXT, event_product_id INTEGER, event_data JSONB)
+RETURNS VOID AS $$
+DECLARE
+    body JSONB;
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM webhooks w WHERE w.is_active AND event_topic = ANY(w.event_types)) THEN
+        RETURN;
+    END IF;
+
+    body := jsonb_build_object(
+        'event_id', nextval('webhook_event_id_seq'),
+        'event_type', event_topic,
+        'data', event_data,
+        'time', NOW()
+    );
+    IF event_product_id IS NOT NULL THEN
+        body := body || jsonb_build_object('product_id', event_product_id);
+    END IF;
+
+    WITH deliveries AS (
+        INSERT INTO webhook_deliveries (webhook_id, event_type, payload)
+        SELECT w.id, event_topic, body
+        FROM webhooks w
+        WHERE w.is_active AND event_topic = ANY(w.event_types)
+        RETURNING id
+    )
+    INSERT INTO event_outbox (topic, payload)
+    SELECT 'webhook', jsonb_build_object('delivery_id', d.id)
+    FROM deliveries d;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Price changes are sent as the live stream describes them
+CREATE OR REPLACE FUNCTION webhook_price_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM enqueue_webhook_deliveries('price_change', NEW.product_id, jsonb_build_object(
+        'product_id', NEW.product_id,
+        'new_price', NEW.new_price,
+        'changed_at', NEW.created_at,
+        'price_change', NEW.new_price - NEW.old_price,
+        'change_type', CASE
+            WHEN NEW.new_price > NEW.old_price THEN 'increase'
+            WHEN NEW.new_price < NEW.old_price THEN 'decrease'
+            ELSE 'unchanged'
+        END,
+        'product_name', (SELECT p.name FROM products p WHERE p.id = NEW.product_id),
+        'source', NEW.source,
+        'price_type', NEW.price_type
+    ));
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_webhook_price_change
+AFTER INSERT ON price_adjustments
+FOR EACH ROW
+EXECUTE FUNCTION webhook_price_change();
+
+-- A stock change is also a restock when the quantity went up, and a low-stock alert when it
+-- fell to the product's threshold
+CREATE OR REPLACE FUNCTION notify_stock_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    payload JSONB := jsonb_build_object(
+        'product_id', NEW.product_id,
+        'old_quantity', OLD.quantity,
+        'quantity', NEW.quantity,
+        'stock_threshold', NEW.stock_threshold
+    );
+BEGIN
+    PERFORM pg_notify('stock_change', payload::text);
+    PERFORM enqueue_webhook_deliveries('stock_change', NEW.product_id, payload);
+    IF NEW.quantity > OLD.quantity THEN
+        PERFORM enqueue_webhook_deliveries('restock', NEW.product_id, payload);
+    END IF;
+    IF NEW.quantity <= NEW.stock_threshold AND OLD.quantity > NEW.stock_threshold THEN
+        PERFORM enqueue_webhook_deliveries('low_stock', NEW.product_id, payload);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_order_status()
+RETURNS TRIGGER AS $$
+DECLARE
+    payload JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.status IS NOT DISTINCT FROM NEW.status THEN
+        RETURN NEW;
+    END IF;
+    payload := jsonb_build_object(
+        'order_id', NEW.id,
+        'customer_id', NEW.customer_id,
+        'old_status', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        'status', NEW.status,
+        'total_price', NEW.total_price
+    );
+    PERFORM pg_notify('order_status', payload::text);
+    PERFORM enqueue_webhook_deliveries('order_status', NULL, payload);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_order_status()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.status IS NOT DISTINCT FROM NEW.status THEN
+        RETURN NEW;
+    END IF;
+    PERFORM pg_notify('order_status', json_build_object(
+        'order_id', NEW.id,
+        'customer_id', NEW.customer_id,
+        'old_status', CASE WHEN TG_OP = 'UPDATE' THEN OLD.status END,
+        'status', NEW.status,
+        'total_price', NEW.total_price
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_stock_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('stock_change', json_build_object(
+        'product_id', NEW.product_id,
+        'old_quantity', OLD.quantity,
+        'quantity', NEW.quantity,
+        'stock_threshold', NEW.stock_threshold
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trigger_webhook_price_change ON price_adjustments;
+DROP FUNCTION IF EXISTS webhook_price_change();
+DROP FUNCTION IF EXISTS enqueue_webhook_deliveries(TEXT, INTEGER, JSONB);
+DROP SEQUENCE IF EXISTS webhook_event_id_seq;
+-- +goose StatementEnd
@@ -126,9 +126,11 @@ INSERT INTO price_model_coefficients (
   category_percentile_coef,
   review_score_coef,
   wishlist_to_sales_ratio_coef,
-  days_since_restock_coef
+  days_since_restock_coef,
+  status,
+  promoted_at
 ) VALUES
-('v1.0', NOW(), 0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0);
+('v1.0', NOW(), 0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 'active', NOW());
 
 -- +goose StatementEnd
 
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/config"
+)
+
+// HubConfig holds the event hub configuration
+type HubConfig struct {
+	BufferSize int
+	ReplaySize int
+	DropPolicy DropPolicy
+}
+
+// LoadHubConfig loads the event hub configuration from environment variables
+func LoadHubConfig() (*HubConfig, error) {
+	log.Printf("Loading event hub config")
+	cfg := &HubConfig{
+		BufferSize: DefaultBufferSize,
+		ReplaySize: DefaultReplaySize,
+		DropPolicy: DropDisconnect,
+	}
+	if bufferSize := config.GetEnv("EVENTS_BUFFER_SIZE"); bufferSize != "" {
+		n, err := strconv.Atoi(bufferSize)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid EVENTS_BUFFER_SIZE: %q", bufferSize)
+		}
+		cfg.BufferSize = n
+	}
+	if replaySize := config.GetEnv("EVENTS_REPLAY_SIZE"); replaySize != "" {
+		n, err := strconv.Atoi(replaySize)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid EVENTS_REPLAY_SIZE: %q", replaySize)
+		}
+		cfg.ReplaySize = n
+	}
+	if dropPolicy := config.GetEnv("EVENTS_DROP_POLICY"); dropPolicy != "" {
+		policy, err := ParseDropPolicy(dropPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EVENTS_DROP_POLICY: %w", err)
+		}
+		cfg.DropPolicy = policy
+	}
+	return cfg, nil
+}
@@ -0,0 +1,349 @@
+// Package events fans live events out to subscribers by topic. Each subscriber picks the
+// topics it wants and can narrow them to some products or categories; admin-only and
+// per-customer topics are only delivered to subscribers allowed to see them.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Topic names a stream of events
+type Topic string
+
+const (
+	TopicPriceChange Topic = "price_change"
+	TopicStockChange Topic = "stock_change"
+	TopicRestock     Topic = "restock"
+	TopicOrderStatus Topic = "order_status"
+	TopicCartPrice   Topic = "cart_price_change"
+	TopicLowStock    Topic = "low_stock"
+)
+
+// Audience is who may subscribe to a topic
+type Audience int
+
+const (
+	// AudiencePublic is anyone, signed in or not
+	AudiencePublic Audience = iota
+	// AudienceCustomer is a signed-in customer, who only receives their own events
+	AudienceCustomer
+	// AudienceAdmin is a signed-in admin, who receives every event
+	AudienceAdmin
+)
+
+// topicAudiences is the audience each topic needs at least
+var topicAudiences = map[Topic]Audience{
+	TopicPriceChange: AudiencePublic,
+	TopicStockChange: AudiencePublic,
+	TopicRestock:     AudiencePublic,
+	TopicOrderStatus: AudienceCustomer,
+	TopicCartPrice:   AudienceCustomer,
+	TopicLowStock:    AudienceAdmin,
+}
+
+// Topics returns the topics an audience may subscribe to
+func Topics(audience Audience) []Topic {
+	var topics []Topic
+	for _, topic := range []Topic{TopicPriceChange, TopicStockChange, TopicRestock, TopicOrderStatus, TopicCartPrice, TopicLowStock} {
+		if topicAudiences[topic] <= audience {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+const (
+	// DefaultBufferSize is how many events a subscriber can fall behind before it is dropped
+	DefaultBufferSize = 64
+	// DefaultReplaySize is how many recent events are kept for reconnecting subscribers
+	DefaultReplaySize = 1024
+)
+
+// DropPolicy is what the hub does when a subscriber's queue is full
+type DropPolicy string
+
+const (
+	// DropDisconnect ends the subscription, so the subscriber reconnects and catches up
+	DropDisconnect DropPolicy = "disconnect"
+	// DropOldest discards the oldest queued event to make room for the new one
+	DropOldest DropPolicy = "oldest"
+)
+
+// ParseDropPolicy reads a drop policy by name
+func ParseDropPolicy(name string) (DropPolicy, error) {
+	switch policy := DropPolicy(name); policy {
+	case DropDisconnect, DropOldest:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown drop policy: %q", name)
+	}
+}
+
+// Event is one thing that happened. Ids increase with every event published, across restarts
+// too. ProductId and CategoryId are set for product events and CustomerId for events that
+// belong to a customer.
+type Event struct {
+	Id         uint64          `json:"id"`
+	Topic      Topic           `json:"topic"`
+	ProductId  int             `json:"product_id,omitempty"`
+	CategoryId int             `json:"category_id,omitempty"`
+	CustomerId int             `json:"-"`
+	Data       json.RawMessage `json:"data"`
+	Time       time.Time       `json:"time"`
+}
+
+// Filter is what a subscriber receives: its topics, narrowed to the given products and
+// categories (either matching) when any are set. Product filters do not apply to events
+// that are not about a product.
+type Filter struct {
+	Audience    Audience
+	CustomerId  int
+	Topics      map[Topic]bool
+	ProductIds  map[int]bool
+	CategoryIds map[int]bool
+}
+
+// Match reports whether an event passes the filter
+func (f *Filter) Match(e *Event) bool {
+	if !f.Topics[e.Topic] || topicAudiences[e.Topic] > f.Audience {
+		return false
+	}
+	if f.Audience == AudienceCustomer && topicAudiences[e.Topic] == AudienceCustomer && e.CustomerId != f.CustomerId {
+		return false
+	}
+	if e.ProductId == 0 || (len(f.ProductIds) == 0 && len(f.CategoryIds) == 0) {
+		return true
+	}
+	return f.ProductIds[e.ProductId] || f.CategoryIds[e.CategoryId]
+}
+
+// Subscription receives the events that pass its filter on C, queued up to the hub's
+// BufferSize. C is closed when the subscription ends, including when the subscriber falls
+// too far behind under DropDisconnect. StartId is the id of the latest event published
+// before it started.
+type Subscription struct {
+	C       <-chan *Event
+	StartId uint64
+	ch      chan *Event
+	filter  Filter
+	dropped atomic.Uint64
+}
+
+// Dropped returns how many of the subscription's events were discarded under DropOldest
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// HubStats is a snapshot of the hub's subscribers and how well they keep up
+type HubStats struct {
+	Clients       int        `json:"clients"`
+	Published     uint64     `json:"published"`
+	Dropped       uint64     `json:"dropped"`
+	Disconnected  uint64     `json:"disconnected"`
+	QueueDepth    int        `json:"queue_depth"`
+	MaxQueueDepth int        `json:"max_queue_depth"`
+	BufferSize    int        `json:"buffer_size"`
+	DropPolicy    DropPolicy `json:"drop_policy"`
+}
+
+// Hub delivers published events to every matching subscription and keeps the most recent
+// ones so that a subscriber that reconnects can catch up on what it missed
+type Hub struct {
+	mutex         sync.Mutex
+	subscriptions map[*Subscription]struct{}
+	lastId        uint64
+	replay        []*Event
+	replayStart   int
+	published     uint64
+	dropped       uint64
+	disconnected  uint64
+	closed        bool
+	BufferSize    int
+	DropPolicy    DropPolicy
+}
+
+func NewHub(cfg *HubConfig) *Hub {
+	return &Hub{
+		subscriptions: map[*Subscription]struct{}{},
+		// Ids start from the clock so that they keep increasing after a restart, and a
+		// subscriber resuming from before it is told to resync rather than given stale events
+		lastId:     uint64(time.Now().UnixMicro()),
+		replay:     make([]*Event, 0, cfg.ReplaySize),
+		BufferSize: cfg.BufferSize,
+		DropPolicy: cfg.DropPolicy,
+	}
+}
+
+// Subscribe starts a subscription; end it with Unsubscribe
+func (h *Hub) Subscribe(filter Filter) *Subscription {
+	sub, _, _ := h.SubscribeFrom(filter, 0)
+	return sub
+}
+
+// SubscribeFrom starts a subscription for a subscriber that last saw event lastId, and returns
+// the matching events it missed. When some of the missed events are no longer kept, resync is
+// true and nothing is replayed; the subscriber has to reload its state instead. A zero lastId
+// starts from now.
+func (h *Hub) SubscribeFrom(filter Filter, lastId uint64) (sub *Subscription, missed []*Event, resync bool) {
+	ch := make(chan *Event, h.BufferSize)
+	sub = &Subscription{C: ch, ch: ch, filter: filter}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	sub.StartId = h.lastId
+	if h.closed {
+		close(ch)
+		return sub, nil, false
+	}
+	h.subscriptions[sub] = struct{}{}
+	if lastId == 0 || lastId == h.lastId {
+		return sub, nil, false
+	}
+	if lastId > h.lastId || len(h.replay) == 0 || lastId+1 < h.replayAt(0).Id {
+		return sub, nil, true
+	}
+	for i := range h.replay {
+		if e := h.replayAt(i); e.Id > lastId && filter.Match(e) {
+			missed = append(missed, e)
+		}
+	}
+	return sub, missed, false
+}
+
+// replayAt returns the i-th oldest kept event; the caller holds the lock
+func (h *Hub) replayAt(i int) *Event {
+	return h.replay[(h.replayStart+i)%len(h.replay)]
+}
+
+// keep adds an event to the replay buffer, evicting the oldest when full; the caller holds the lock
+func (h *Hub) keep(e *Event) {
+	if cap(h.replay) == 0 {
+		return
+	}
+	if len(h.replay) < cap(h.replay) {
+		h.replay = append(h.replay, e)
+		return
+	}
+	h.replay[h.replayStart] = e
+	h.replayStart = (h.replayStart + 1) % len(h.replay)
+}
+
+// SetFilter changes what a subscription receives from the next event published on
+func (h *Hub) SetFilter(sub *Subscription, filter Filter) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	sub.filter = filter
+}
+
+// Unsubscribe ends a subscription. It is safe to call more than once.
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.remove(sub)
+}
+
+// Close ends every subscription, so that the streams serving them finish, and ends any
+// subscription started afterwards straight away
+func (h *Hub) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.closed = true
+	for sub := range h.subscriptions {
+		h.remove(sub)
+	}
+}
+
+// Closed reports whether the hub was closed
+func (h *Hub) Closed() bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.closed
+}
+
+// remove closes and forgets a subscription; the caller holds the lock
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subscriptions[sub]; ok {
+		delete(h.subscriptions, sub)
+		close(sub.ch)
+	}
+}
+
+// Publish numbers an event and queues it for every matching subscription without blocking
+func (h *Hub) Publish(e *Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.lastId++
+	h.published++
+	e.Id = h.lastId
+	h.keep(e)
+	for sub := range h.subscriptions {
+		if sub.filter.Match(e) {
+			h.deliver(sub, e)
+		}
+	}
+}
+
+// deliver queues an event for a subscription, applying the drop policy when its queue is
+// full; the caller holds the lock. Only the subscriber receives from the queue, so after
+// discarding the oldest event there is room for the new one.
+func (h *Hub) deliver(sub *Subscription, e *Event) {
+	select {
+	case sub.ch <- e:
+		return
+	default:
+	}
+	h.dropped++
+	if h.DropPolicy != DropOldest {
+		h.disconnected++
+		h.remove(sub)
+		return
+	}
+	select {
+	case <-sub.ch:
+	default:
+	}
+	sub.dropped.Add(1)
+	select {
+	case sub.ch <- e:
+	default:
+	}
+}
+
+// Stats returns a snapshot of the hub's subscribers and counters since it started
+func (h *Hub) Stats() HubStats {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	stats := HubStats{
+		Clients:      len(h.subscriptions),
+		Published:    h.published,
+		Dropped:      h.dropped,
+		Disconnected: h.disconnected,
+		BufferSize:   h.BufferSize,
+		DropPolicy:   h.DropPolicy,
+	}
+	for sub := range h.subscriptions {
+		depth := len(sub.ch)
+		stats.QueueDepth += depth
+		stats.MaxQueueDepth = max(stats.MaxQueueDepth, depth)
+	}
+	return stats
+}
+
+// PublishData encodes data as the event's payload and publishes it
+func (h *Hub) PublishData(e *Event, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	e.Data = payload
+	h.Publish(e)
+	return nil
+}
@@ -0,0 +1,173 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// heartbeatInterval is how often an idle stream sends a comment to keep the connection open
+const heartbeatInterval = 30 * time.Second
+
+// ParseFilter reads a subscriber's filter from the query: ?topics, ?product_ids and
+// ?category_ids, each comma separated. Without ?topics the subscriber gets every topic its
+// audience may see.
+func ParseFilter(query url.Values, audience Audience, customerId int) (Filter, error) {
+	filter := Filter{
+		Audience:   audience,
+		CustomerId: customerId,
+		Topics:     map[Topic]bool{},
+	}
+
+	if param := query.Get("topics"); param != "" {
+		for _, name := range strings.Split(param, ",") {
+			topic, err := parseTopic(strings.TrimSpace(name), audience)
+			if err != nil {
+				return filter, err
+			}
+			filter.Topics[topic] = true
+		}
+	} else {
+		for _, topic := range Topics(audience) {
+			filter.Topics[topic] = true
+		}
+	}
+
+	var err error
+	if filter.ProductIds, err = parseIds(query.Get("product_ids")); err != nil {
+		return filter, fmt.Errorf("invalid product_ids: %w", err)
+	}
+	if filter.CategoryIds, err = parseIds(query.Get("category_ids")); err != nil {
+		return filter, fmt.Errorf("invalid category_ids: %w", err)
+	}
+	return filter, nil
+}
+
+// parseTopic reads a topic by name, checking the audience may subscribe to it
+func parseTopic(name string, audience Audience) (Topic, error) {
+	topic := Topic(name)
+	required, ok := topicAudiences[topic]
+	if !ok {
+		return "", fmt.Errorf("unknown topic: %q", topic)
+	}
+	if required > audience {
+		return "", fmt.Errorf("topic %q is not available here", topic)
+	}
+	return topic, nil
+}
+
+func parseIds(param string) (map[int]bool, error) {
+	if param == "" {
+		return nil, nil
+	}
+	ids := map[int]bool{}
+	for _, part := range strings.Split(param, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// lastEventId reads the id of the last event a reconnecting client saw, from the
+// Last-Event-ID header browsers send or from ?last_event_id
+func lastEventId(c echo.Context) uint64 {
+	param := c.Request().Header.Get("Last-Event-ID")
+	if param == "" {
+		param = c.QueryParam("last_event_id")
+	}
+	id, _ := strconv.ParseUint(param, 10, 64)
+	return id
+}
+
+// ServeSSE streams the hub's events matching the request's filter as Server-Sent Events,
+// each named after its topic, until the client disconnects or falls too far behind.
+// A client that reconnects with Last-Event-ID first gets the events it missed, or a resync
+// event when they are no longer kept.
+func ServeSSE(c echo.Context, hub *Hub, audience Audience, customerId int) error {
+	filter, err := ParseFilter(c.QueryParams(), audience, customerId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	topics := make([]Topic, 0, len(filter.Topics))
+	for topic := range filter.Topics {
+		topics = append(topics, topic)
+	}
+	return Stream(c, hub, filter, map[string]interface{}{"topics": topics}, func(w io.Writer, e *Event) error {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Id, e.Topic, data)
+		return err
+	})
+}
+
+// Stream serves a subscription as Server-Sent Events, writing each event with write.
+// It starts with a connect event carrying connected, replays what a reconnecting client
+// missed, and sends a heartbeat comment while idle.
+func Stream(c echo.Context, hub *Hub, filter Filter, connected interface{}, write func(w io.Writer, e *Event) error) error {
+	w := c.Response()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	sub, missed, resync := hub.SubscribeFrom(filter, lastEventId(c))
+	defer hub.Unsubscribe(sub)
+
+	connectedJSON, _ := json.Marshal(connected)
+	fmt.Fprintf(w, "event: connect\ndata: %s\n\n", connectedJSON)
+	if resync {
+		// The missed events are gone; the client reloads its state and carries on from here
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: resync\ndata: {}\n\n", sub.StartId); err != nil {
+			return err
+		}
+	}
+	for _, e := range missed {
+		if err := write(w, e); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	var dropped uint64
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			// Events discarded to keep up leave a gap the client has to reload over
+			if n := sub.Dropped(); n > dropped {
+				dropped = n
+				if _, err := fmt.Fprintf(w, "event: resync\ndata: {}\n\n"); err != nil {
+					return err
+				}
+			}
+			if err := write(w, e); err != nil {
+				return err
+			}
+			w.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprintf(w, ": heartbeat %d\n\n", time.Now().Unix()); err != nil {
+				return err
+			}
+			w.Flush()
+		case <-c.Request().Context().Done():
+			return nil
+		}
+	}
+}
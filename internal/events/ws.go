@@ -0,0 +1,265 @@
+package events
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// Messages a WebSocket client sends to change what it receives
+const (
+	wsSubscribe   = "subscribe"
+	wsUnsubscribe = "unsubscribe"
+)
+
+// wsRequest is a message from a WebSocket client. Subscribe adds the topics, products and
+// categories to the connection's filter, or every topic its audience may see when it names
+// none. Unsubscribe removes them, or every topic when it names none.
+type wsRequest struct {
+	Type        string   `json:"type"`
+	Topics      []string `json:"topics"`
+	ProductIds  []int    `json:"product_ids"`
+	CategoryIds []int    `json:"category_ids"`
+}
+
+// wsMessage is a message to a WebSocket client: connect, subscribed, event, resync,
+// heartbeat or error
+type wsMessage struct {
+	Type        string  `json:"type"`
+	Topics      []Topic `json:"topics,omitempty"`
+	ProductIds  []int   `json:"product_ids,omitempty"`
+	CategoryIds []int   `json:"category_ids,omitempty"`
+	Event       *Event  `json:"event,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+const (
+	// WSProtocol is the subprotocol WebSocket clients ask for. Browsers cannot set headers on
+	// a WebSocket, so a signed-in client offers its token as a second "bearer.<token>"
+	// subprotocol rather than in the URL, where it would end up in access logs.
+	WSProtocol = "pulse.v1"
+	// wsTokenPrefix marks the subprotocol carrying a client's token
+	wsTokenPrefix = "bearer."
+)
+
+// wsProtocols returns the subprotocols a WebSocket client offered
+func wsProtocols(r *http.Request) []string {
+	var protocols []string
+	for _, protocol := range strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",") {
+		if protocol = strings.TrimSpace(protocol); protocol != "" {
+			protocols = append(protocols, protocol)
+		}
+	}
+	return protocols
+}
+
+// WSToken returns the token a WebSocket client offered as a subprotocol, or "" without one
+func WSToken(r *http.Request) string {
+	for _, protocol := range wsProtocols(r) {
+		if token, ok := strings.CutPrefix(protocol, wsTokenPrefix); ok {
+			return token
+		}
+	}
+	return ""
+}
+
+// handshake refuses WebSocket handshakes from browser pages outside allowedOrigins, which
+// CORS does not cover; clients that send no Origin are not browsers and are let through. It
+// answers with WSProtocol when the client asked for it, and never echoes the token back.
+func handshake(allowedOrigins []string) func(*websocket.Config, *http.Request) error {
+	return func(config *websocket.Config, r *http.Request) error {
+		origin := r.Header.Get(echo.HeaderOrigin)
+		if origin != "" && !slices.Contains(allowedOrigins, origin) {
+			return fmt.Errorf("origin %q is not allowed", origin)
+		}
+		config.Protocol = nil
+		if slices.Contains(wsProtocols(r), WSProtocol) {
+			config.Protocol = []string{WSProtocol}
+		}
+		return nil
+	}
+}
+
+// ServeWS serves the hub's events over a WebSocket to clients on allowedOrigins. The
+// connection starts with the filter in the query, like ServeSSE, including replay from
+// ?last_event_id, and the client changes it with subscribe and unsubscribe messages. Events
+// arrive as event messages; a resync message means some were missed and the client has to
+// reload its state.
+func ServeWS(c echo.Context, hub *Hub, audience Audience, customerId int, allowedOrigins []string) error {
+	filter, err := ParseFilter(c.QueryParams(), audience, customerId)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	lastId := lastEventId(c)
+
+	server := websocket.Server{Handshake: handshake(allowedOrigins)}
+	server.Handler = func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		sub, missed, resync := hub.SubscribeFrom(filter, lastId)
+		defer hub.Unsubscribe(sub)
+
+		if err := websocket.JSON.Send(ws, subscribed("connect", filter)); err != nil {
+			return
+		}
+		if resync {
+			if err := websocket.JSON.Send(ws, wsMessage{Type: "resync"}); err != nil {
+				return
+			}
+		}
+		for _, e := range missed {
+			if err := websocket.JSON.Send(ws, wsMessage{Type: "event", Event: e}); err != nil {
+				return
+			}
+		}
+
+		// Requests are read on their own goroutine and applied here, so that only this loop
+		// writes to the connection
+		requests := make(chan wsRequest)
+		closed := make(chan struct{})
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			defer close(closed)
+			for {
+				var req wsRequest
+				if err := websocket.JSON.Receive(ws, &req); err != nil {
+					return
+				}
+				select {
+				case requests <- req:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		heartbeat := time.NewTicker(heartbeatInterval)
+		defer heartbeat.Stop()
+		var dropped uint64
+		for {
+			var msg wsMessage
+			select {
+			case e, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				if n := sub.Dropped(); n > dropped {
+					dropped = n
+					if err := websocket.JSON.Send(ws, wsMessage{Type: "resync"}); err != nil {
+						return
+					}
+				}
+				msg = wsMessage{Type: "event", Event: e}
+			case req := <-requests:
+				if err := applyRequest(&filter, req); err != nil {
+					msg = wsMessage{Type: "error", Error: err.Error()}
+					break
+				}
+				hub.SetFilter(sub, filter)
+				msg = subscribed("subscribed", filter)
+			case <-heartbeat.C:
+				msg = wsMessage{Type: "heartbeat"}
+			case <-closed:
+				return
+			case <-c.Request().Context().Done():
+				return
+			}
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				return
+			}
+		}
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// applyRequest changes a connection's filter as a client asked; the filter is left as it was
+// when the request is invalid
+func applyRequest(filter *Filter, req wsRequest) error {
+	topics := make([]Topic, 0, len(req.Topics))
+	for _, name := range req.Topics {
+		topic, err := parseTopic(name, filter.Audience)
+		if err != nil {
+			return err
+		}
+		topics = append(topics, topic)
+	}
+
+	switch req.Type {
+	case wsSubscribe:
+		if len(topics) == 0 && len(req.ProductIds) == 0 && len(req.CategoryIds) == 0 {
+			topics = Topics(filter.Audience)
+		}
+		filter.Topics = with(filter.Topics, topics)
+		filter.ProductIds = with(filter.ProductIds, req.ProductIds)
+		filter.CategoryIds = with(filter.CategoryIds, req.CategoryIds)
+	case wsUnsubscribe:
+		if len(topics) == 0 && len(req.ProductIds) == 0 && len(req.CategoryIds) == 0 {
+			filter.Topics = map[Topic]bool{}
+			return nil
+		}
+		filter.Topics = without(filter.Topics, topics)
+		filter.ProductIds = without(filter.ProductIds, req.ProductIds)
+		filter.CategoryIds = without(filter.CategoryIds, req.CategoryIds)
+	default:
+		return fmt.Errorf("unknown message type: %q", req.Type)
+	}
+	return nil
+}
+
+// with returns a copy of set with keys added, since the hub may still be matching events
+// against the original
+func with[K comparable](set map[K]bool, keys []K) map[K]bool {
+	if len(keys) == 0 {
+		return set
+	}
+	result := make(map[K]bool, len(set)+len(keys))
+	for key := range set {
+		result[key] = true
+	}
+	for _, key := range keys {
+		result[key] = true
+	}
+	return result
+}
+
+// without returns a copy of set with keys removed
+func without[K comparable](set map[K]bool, keys []K) map[K]bool {
+	if len(keys) == 0 {
+		return set
+	}
+	result := make(map[K]bool, len(set))
+	for key := range set {
+		result[key] = true
+	}
+	for _, key := range keys {
+		delete(result, key)
+	}
+	return result
+}
+
+// subscribed describes a connection's filter to its client
+func subscribed(messageType string, filter Filter) wsMessage {
+	msg := wsMessage{Type: messageType, Topics: []Topic{}}
+	for _, topic := range Topics(filter.Audience) {
+		if filter.Topics[topic] {
+			msg.Topics = append(msg.Topics, topic)
+		}
+	}
+	for id := range filter.ProductIds {
+		msg.ProductIds = append(msg.ProductIds, id)
+	}
+	for id := range filter.CategoryIds {
+		msg.CategoryIds = append(msg.CategoryIds, id)
+	}
+	sort.Ints(msg.ProductIds)
+	sort.Ints(msg.CategoryIds)
+	return msg
+}
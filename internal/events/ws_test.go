@@ -0,0 +1,136 @@
+package events
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// dial sends a WebSocket handshake with the given extra headers and returns the response
+func dial(t *testing.T, addr string, headers map[string]string) *http.Response {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	request := "GET /ws HTTP/1.1\r\n" +
+		"Host: " + addr + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n"
+	for name, value := range headers {
+		request += name + ": " + value + "\r\n"
+	}
+	if _, err = fmt.Fprint(conn, request+"\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// serveWS starts a server with a WebSocket at /ws accepting the given origins
+func serveWS(t *testing.T, allowedOrigins []string) string {
+	t.Helper()
+	hub := NewHub(&HubConfig{BufferSize: DefaultBufferSize, ReplaySize: DefaultReplaySize, DropPolicy: DropDisconnect})
+	e := echo.New()
+	e.GET("/ws", func(c echo.Context) error {
+		return ServeWS(c, hub, AudiencePublic, 0, allowedOrigins)
+	})
+	server := httptest.NewServer(e)
+	t.Cleanup(func() {
+		hub.Close()
+		server.Close()
+	})
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestServeWSOrigins(t *testing.T) {
+	addr := serveWS(t, []string{"http://localhost:5185"})
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{name: "allowed origin", origin: "http://localhost:5185", status: http.StatusSwitchingProtocols},
+		{name: "no origin", status: http.StatusSwitchingProtocols},
+		{name: "other origin", origin: "http://evil.example", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.origin != "" {
+				headers["Origin"] = tt.origin
+			}
+			if resp := dial(t, addr, headers); resp.StatusCode != tt.status {
+				t.Errorf("handshake status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestServeWSProtocol(t *testing.T) {
+	addr := serveWS(t, nil)
+
+	tests := []struct {
+		name      string
+		protocols string
+		want      string
+	}{
+		{name: "token offered with the protocol", protocols: WSProtocol + ", bearer.abc.def.ghi", want: WSProtocol},
+		{name: "protocol alone", protocols: WSProtocol, want: WSProtocol},
+		{name: "no protocol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{}
+			if tt.protocols != "" {
+				headers["Sec-WebSocket-Protocol"] = tt.protocols
+			}
+			resp := dial(t, addr, headers)
+			if resp.StatusCode != http.StatusSwitchingProtocols {
+				t.Fatalf("handshake status = %d, want %d", resp.StatusCode, http.StatusSwitchingProtocols)
+			}
+			if got := resp.Header.Get("Sec-WebSocket-Protocol"); got != tt.want {
+				t.Errorf("Sec-WebSocket-Protocol = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWSToken(t *testing.T) {
+	tests := []struct {
+		protocols string
+		want      string
+	}{
+		{protocols: WSProtocol + ", bearer.abc.def.ghi", want: "abc.def.ghi"},
+		{protocols: "bearer.xyz," + WSProtocol, want: "xyz"},
+		{protocols: WSProtocol},
+		{},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		if tt.protocols != "" {
+			r.Header.Set("Sec-WebSocket-Protocol", tt.protocols)
+		}
+		if got := WSToken(r); got != tt.want {
+			t.Errorf("WSToken(%q) = %q, want %q", tt.protocols, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+// Detail retrieves an order with its items, the price each sold at, its payments and its
+// status history
+func Detail(ctx context.Context, db *sqlx.DB, orderId int) (*repo.OrderWithItems, error) {
+	var order repo.OrderWithItems
+	getOrderQuery := `
+		SELECT id, customer_id, total_price, status, price_valid_until, created_at, updated_at
+		FROM orders
+		WHERE id = $1
+	`
+	err := db.GetContext(ctx, &order, getOrderQuery, orderId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	getItemsQuery := `
+		SELECT oi.id, oi.order_id, oi.product_id, oi.price, oi.quantity, oi.created_at, oi.updated_at,
+				p.name as product_name, p.image_path as product_image_path,
+				(SELECT s.sale_price FROM sales s WHERE s.order_item_id = oi.id ORDER BY s.id DESC LIMIT 1) AS sale_price
+		FROM order_items oi
+		JOIN products p ON oi.product_id = p.id
+		WHERE order_id = $1
+		ORDER BY oi.created_at
+	`
+	if err = db.SelectContext(ctx, &order.Items, getItemsQuery, order.Id); err != nil {
+		return nil, fmt.Errorf("failed to get order items: %w", err)
+	}
+
+	getPaymentsQuery := `
+		SELECT id, order_id, payment_method, amount, status, COALESCE(transaction_id, '') AS transaction_id, created_at, updated_at
+		FROM payments
+		WHERE order_id = $1
+		ORDER BY created_at
+	`
+	if err = db.SelectContext(ctx, &order.Payments, getPaymentsQuery, order.Id); err != nil {
+		return nil, fmt.Errorf("failed to get order payments: %w", err)
+	}
+
+	if order.History, err = History(ctx, db, order.Id); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
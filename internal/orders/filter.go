@@ -0,0 +1,54 @@
+package orders
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+// ParseFilter reads the order list parameters shared by customers and admins: ?status, ?from
+// and ?to (YYYY-MM-DD, both inclusive), ?page and ?page_size. The page size defaults to
+// defaultPageSize and may not exceed maxPageSize.
+func ParseFilter(query url.Values, defaultPageSize, maxPageSize int) (repo.OrderFilter, error) {
+	filter := repo.OrderFilter{Page: 1, PageSize: defaultPageSize}
+
+	filter.Status = repo.OrderStatus(query.Get("status"))
+	if filter.Status != "" && !IsStatus(filter.Status) {
+		return filter, errors.New("invalid status")
+	}
+
+	if param := query.Get("from"); param != "" {
+		from, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			return filter, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		filter.From = &from
+	}
+	if param := query.Get("to"); param != "" {
+		to, err := time.Parse("2006-01-02", param)
+		if err != nil {
+			return filter, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+
+	if param := query.Get("page"); param != "" {
+		page, err := strconv.Atoi(param)
+		if err != nil || page <= 0 {
+			return filter, errors.New("invalid page")
+		}
+		filter.Page = page
+	}
+	if param := query.Get("page_size"); param != "" {
+		pageSize, err := strconv.Atoi(param)
+		if err != nil || pageSize <= 0 || pageSize > maxPageSize {
+			return filter, errors.New("invalid page_size")
+		}
+		filter.PageSize = pageSize
+	}
+	return filter, nil
+}
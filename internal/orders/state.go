@@ -0,0 +1,143 @@
+// Package orders moves orders through their lifecycle. Every status change goes through
+// Transition, which checks it is allowed and records it in the order's status history.
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrOrderNotFound     = errors.New("order not found")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
+// InvalidTransitionError is a status change the state machine does not allow
+type InvalidTransitionError struct {
+	From repo.OrderStatus
+	To   repo.OrderStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("an order cannot go from %s to %s", e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Unwrap() error { return ErrInvalidTransition }
+
+// transitions are the statuses an order can move to from each status. An order is placed
+// pending, and is paid, or cancelled or failed before it is; a paid order is fulfilled
+// through processing, shipped and delivered, and can be refunded until it is shipped and
+// again once it is delivered. Cancelled, refunded and failed orders are final.
+var transitions = map[repo.OrderStatus][]repo.OrderStatus{
+	repo.OrderStatusPending:    {repo.OrderStatusPaid, repo.OrderStatusCancelled, repo.OrderStatusFailed},
+	repo.OrderStatusPaid:       {repo.OrderStatusProcessing, repo.OrderStatusRefunded},
+	repo.OrderStatusProcessing: {repo.OrderStatusShipped, repo.OrderStatusRefunded},
+	repo.OrderStatusShipped:    {repo.OrderStatusDelivered},
+	repo.OrderStatusDelivered:  {repo.OrderStatusRefunded},
+	repo.OrderStatusCancelled:  {},
+	repo.OrderStatusRefunded:   {},
+	repo.OrderStatusFailed:     {},
+}
+
+// Purchased are the statuses of orders that were paid for and not refunded
+var Purchased = []repo.OrderStatus{
+	repo.OrderStatusPaid, repo.OrderStatusProcessing, repo.OrderStatusShipped, repo.OrderStatusDelivered,
+}
+
+// IsStatus reports whether status is an order status
+func IsStatus(status repo.OrderStatus) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+// CanTransition reports whether an order can move from one status to another
+func CanTransition(from, to repo.OrderStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// NextStatuses returns the statuses an order in status can move to
+func NextStatuses(status repo.OrderStatus) []repo.OrderStatus {
+	return append([]repo.OrderStatus{}, transitions[status]...)
+}
+
+// Actor is who changes an order's status; Name is empty for the system
+type Actor struct {
+	Type repo.ActorType
+	Name string
+}
+
+var System = Actor{Type: repo.ActorTypeSystem}
+
+// Customer is the customer with userId acting on their own order
+func Customer(userId int) Actor {
+	return Actor{Type: repo.ActorTypeCustomer, Name: strconv.Itoa(userId)}
+}
+
+// Admin is the admin with username acting on an order
+func Admin(username string) Actor {
+	return Actor{Type: repo.ActorTypeAdmin, Name: username}
+}
+
+// record adds a status change to an order's history
+func record(ctx context.Context, tx *sqlx.Tx, orderId int, from *repo.OrderStatus, to repo.OrderStatus, actor Actor, reason string) error {
+	query := `
+		INSERT INTO order_status_history (order_id, from_status, to_status, actor_type, actor, reason)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+	`
+	if _, err := tx.ExecContext(ctx, query, orderId, from, to, actor.Type, actor.Name, reason); err != nil {
+		return fmt.Errorf("failed to record status of order %d: %w", orderId, err)
+	}
+	return nil
+}
+
+// Placed records a new order's first status in its history
+func Placed(ctx context.Context, tx *sqlx.Tx, order *repo.Order, actor Actor) error {
+	return record(ctx, tx, order.Id, nil, order.Status, actor, "")
+}
+
+// Transition moves an order to a new status within tx, when the state machine allows it, and
+// records the change with its actor and reason. It locks the order until tx ends and returns
+// it as updated.
+func Transition(ctx context.Context, tx *sqlx.Tx, orderId int, to repo.OrderStatus, actor Actor, reason string) (*repo.Order, error) {
+	var order repo.Order
+	err := tx.GetContext(ctx, &order, `SELECT * FROM orders WHERE id = $1 FOR UPDATE`, orderId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order %d: %w", orderId, err)
+	}
+
+	from := order.Status
+	if !CanTransition(from, to) {
+		return nil, &InvalidTransitionError{From: from, To: to}
+	}
+	if err = tx.GetContext(ctx, &order, `UPDATE orders SET status = $2 WHERE id = $1 RETURNING *`, orderId, to); err != nil {
+		return nil, fmt.Errorf("failed to update order %d: %w", orderId, err)
+	}
+	if err = record(ctx, tx, orderId, &from, to, actor, reason); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// History retrieves an order's status changes, oldest first
+func History(ctx context.Context, db sqlx.QueryerContext, orderId int) ([]repo.OrderStatusChange, error) {
+	var history []repo.OrderStatusChange
+	query := `SELECT * FROM order_status_history WHERE order_id = $1 ORDER BY id`
+	if err := sqlx.SelectContext(ctx, db, &history, query, orderId); err != nil {
+		return nil, fmt.Errorf("failed to get status history of order %d: %w", orderId, err)
+	}
+	return history, nil
+}
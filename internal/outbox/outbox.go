@@ -0,0 +1,284 @@
+// Package outbox stores events in the same transaction as the change they describe and
+// dispatches them to handlers afterwards, so that an event is never lost when the process is
+// down or a notification is missed.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Topics
+const (
+	TopicSale    = "sale"
+	TopicWebhook = "webhook"
+)
+
+// notifyChannel is the channel the outbox trigger notifies on every new event
+const notifyChannel = "event_outbox"
+
+const (
+	DefaultBatchSize    = 50
+	DefaultMaxAttempts  = 8
+	DefaultPollInterval = 30 * time.Second
+	// DefaultLease is how long a claimed event stays hidden from other dispatchers.
+	// An event whose dispatcher dies mid-handling is retried once its lease runs out.
+	DefaultLease = 5 * time.Minute
+	// DefaultBaseBackoff is the wait before the first retry; each retry doubles it
+	DefaultBaseBackoff = 5 * time.Second
+	// DefaultMaxBackoff caps the wait between retries
+	DefaultMaxBackoff = time.Hour
+)
+
+// SaleEvent announces a sale recorded by a payment
+type SaleEvent struct {
+	SaleId      int     `json:"sale_id"`
+	OrderItemId int     `json:"order_item_id"`
+	ProductId   int     `json:"product_id"`
+	SalePrice   float64 `json:"sale_price"`
+	Quantity    int     `json:"quantity"`
+}
+
+// Enqueue writes an event to the outbox. Pass the transaction of the change the event
+// describes so that the event is stored if and only if the change commits.
+func Enqueue(ctx context.Context, e sqlx.ExecerContext, topic string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", topic, err)
+	}
+	query := `INSERT INTO event_outbox (topic, payload) VALUES ($1, $2)`
+	if _, err = e.ExecContext(ctx, query, topic, data); err != nil {
+		return fmt.Errorf("failed to enqueue %s event: %w", topic, err)
+	}
+	return nil
+}
+
+// Handler processes one event's payload. An error leaves the event to be retried.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// postponedError is a handler error that puts an event off without using up an attempt
+type postponedError struct {
+	err  error
+	wait time.Duration
+}
+
+func (e *postponedError) Error() string { return e.err.Error() }
+func (e *postponedError) Unwrap() error { return e.err }
+
+// Postpone wraps a handler error to retry the event after wait without counting the attempt,
+// for failures that say nothing about the event itself, such as a dependency being down
+func Postpone(err error, wait time.Duration) error {
+	return &postponedError{err: err, wait: wait}
+}
+
+// Postponed reports whether a handler error was postponed, and for how long
+func Postponed(err error) (time.Duration, bool) {
+	var postponed *postponedError
+	if errors.As(err, &postponed) {
+		return postponed.wait, true
+	}
+	return 0, false
+}
+
+// Dispatcher claims pending events and hands them to the handler for their topic.
+// Several dispatchers can run against the same table; SKIP LOCKED keeps them off each
+// other's events. With more than one worker the events of a batch are handled concurrently,
+// so handlers must be safe to call from several goroutines.
+type Dispatcher struct {
+	db           *sqlx.DB
+	handlers     map[string]Handler
+	mutex        sync.RWMutex
+	BatchSize    int
+	Workers      int
+	MaxAttempts  int
+	PollInterval time.Duration
+	Lease        time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+func NewDispatcher(db *sqlx.DB) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		handlers:     map[string]Handler{},
+		BatchSize:    DefaultBatchSize,
+		Workers:      1,
+		MaxAttempts:  DefaultMaxAttempts,
+		PollInterval: DefaultPollInterval,
+		Lease:        DefaultLease,
+		BaseBackoff:  DefaultBaseBackoff,
+		MaxBackoff:   DefaultMaxBackoff,
+	}
+}
+
+// Handle registers the handler for a topic
+func (d *Dispatcher) Handle(topic string, handler Handler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.handlers[topic] = handler
+}
+
+func (d *Dispatcher) handler(topic string) (Handler, bool) {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	handler, ok := d.handlers[topic]
+	return handler, ok
+}
+
+// claim leases up to BatchSize due events. Claiming counts as an attempt, so an event that
+// keeps crashing its dispatcher still runs out of attempts.
+func (d *Dispatcher) claim(ctx context.Context) ([]repo.OutboxEvent, error) {
+	var events []repo.OutboxEvent
+	query := `
+		UPDATE event_outbox
+		SET attempts = attempts + 1, available_at = NOW() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id
+			FROM event_outbox
+			WHERE status = 'pending' AND available_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+	if err := d.db.SelectContext(ctx, &events, query, d.BatchSize, d.Lease.Milliseconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim events: %w", err)
+	}
+	return events, nil
+}
+
+// backoff is the wait before retrying an event that has failed attempts times
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.BaseBackoff
+	for i := 1; i < attempts && wait < d.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.MaxBackoff)
+}
+
+// complete marks an event done, or schedules its retry when the handler failed.
+// An event out of attempts is marked failed and left for inspection.
+func (d *Dispatcher) complete(ctx context.Context, event *repo.OutboxEvent, handleErr error) error {
+	var err error
+	wait, postponed := Postponed(handleErr)
+	switch {
+	case postponed:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE event_outbox
+			SET attempts = attempts - 1, available_at = NOW() + $2 * interval '1 millisecond', last_error = $3
+			WHERE id = $1
+		`, event.Id, wait.Milliseconds(), handleErr.Error())
+	case handleErr == nil:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE event_outbox
+			SET status = 'done', processed_at = NOW(), last_error = NULL
+			WHERE id = $1
+		`, event.Id)
+	case event.Attempts >= d.MaxAttempts:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE event_outbox
+			SET status = 'failed', last_error = $2
+			WHERE id = $1
+		`, event.Id, handleErr.Error())
+	default:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE event_outbox
+			SET available_at = NOW() + $2 * interval '1 millisecond', last_error = $3
+			WHERE id = $1
+		`, event.Id, d.backoff(event.Attempts).Milliseconds(), handleErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to complete event %d: %w", event.Id, err)
+	}
+	return nil
+}
+
+// dispatch hands one claimed event to its handler and records the outcome
+func (d *Dispatcher) dispatch(ctx context.Context, event *repo.OutboxEvent) error {
+	handler, ok := d.handler(event.Topic)
+	var handleErr error
+	if !ok {
+		handleErr = fmt.Errorf("no handler for topic %q", event.Topic)
+	} else {
+		handleErr = handler(ctx, event.Payload)
+	}
+	if _, postponed := Postponed(handleErr); handleErr != nil && !postponed {
+		logging.LogError("Outbox: %s event %d failed on attempt %d: %v", event.Topic, event.Id, event.Attempts, handleErr)
+	}
+	return d.complete(ctx, event, handleErr)
+}
+
+// RunOnce dispatches due events until none are left and returns how many were handled
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	handled := 0
+	for {
+		events, err := d.claim(ctx)
+		if err != nil {
+			return handled, err
+		}
+		if len(events) == 0 {
+			return handled, nil
+		}
+		if err = d.dispatchBatch(ctx, events); err != nil {
+			return handled, err
+		}
+		handled += len(events)
+	}
+}
+
+// dispatchBatch dispatches claimed events, up to Workers at a time
+func (d *Dispatcher) dispatchBatch(ctx context.Context, events []repo.OutboxEvent) error {
+	slots := make(chan struct{}, max(d.Workers, 1))
+	errs := make([]error, len(events))
+	var wg sync.WaitGroup
+	for i := range events {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[i] = d.dispatch(ctx, &events[i])
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Run dispatches events until ctx is cancelled. A notification on the outbox channel wakes it
+// straight away; the poll interval picks up retries and anything a missed notification left.
+func (d *Dispatcher) Run(ctx context.Context, connStr string) {
+	listener := pq.NewListener(connStr, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logging.LogError("Outbox: listener event: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(notifyChannel); err != nil {
+		logging.LogError("Outbox: failed to listen on %s, polling only: %v", notifyChannel, err)
+	}
+
+	poll := time.NewTicker(d.PollInterval)
+	defer poll.Stop()
+	for {
+		if _, err := d.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logging.LogError("Outbox: dispatch failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.Notify:
+		case <-poll.C:
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrEventNotFound  = errors.New("event not found")
+	ErrEventNotFailed = errors.New("only failed events can be retried or discarded")
+)
+
+// Store reads and manages the events of one topic, including the dead letters: events that
+// ran out of attempts
+type Store struct {
+	db    *sqlx.DB
+	topic string
+}
+
+func NewStore(db *sqlx.DB, topic string) *Store {
+	return &Store{db: db, topic: topic}
+}
+
+// List retrieves the topic's events with a status (all when empty), newest first
+func (s *Store) List(ctx context.Context, status repo.OutboxStatus, limit int) ([]repo.OutboxEvent, error) {
+	var events []repo.OutboxEvent
+	query := `
+		SELECT *
+		FROM event_outbox
+		WHERE topic = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	if err := s.db.SelectContext(ctx, &events, query, s.topic, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to get %s events: %w", s.topic, err)
+	}
+	return events, nil
+}
+
+// move changes a failed event's status with query and returns the updated event
+func (s *Store) move(ctx context.Context, id int, query string) (*repo.OutboxEvent, error) {
+	var event repo.OutboxEvent
+	err := s.db.GetContext(ctx, &event, query, id, s.topic)
+	if err == nil {
+		return &event, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to update event %d: %w", id, err)
+	}
+
+	var exists bool
+	err = s.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM event_outbox WHERE id = $1 AND topic = $2)`, id, s.topic)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrEventNotFound
+	}
+	return nil, ErrEventNotFailed
+}
+
+// Retry puts a failed event back in the queue with a fresh set of attempts
+func (s *Store) Retry(ctx context.Context, id int) (*repo.OutboxEvent, error) {
+	event, err := s.move(ctx, id, `
+		UPDATE event_outbox
+		SET status = 'pending', attempts = 0, available_at = NOW()
+		WHERE id = $1 AND topic = $2 AND status = 'failed'
+		RETURNING *
+	`)
+	if err != nil {
+		return nil, err
+	}
+	// Wake the dispatcher rather than wait for its next poll
+	if _, err = s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, notifyChannel, s.topic); err != nil {
+		return nil, fmt.Errorf("failed to notify dispatcher: %w", err)
+	}
+	return event, nil
+}
+
+// Discard gives up on a failed event for good
+func (s *Store) Discard(ctx context.Context, id int) (*repo.OutboxEvent, error) {
+	return s.move(ctx, id, `
+		UPDATE event_outbox
+		SET status = 'discarded'
+		WHERE id = $1 AND topic = $2 AND status = 'failed'
+		RETURNING *
+	`)
+}
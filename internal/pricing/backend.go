@@ -0,0 +1,190 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/config"
+	"github.com/jmoiron/sqlx"
+)
+
+// PricingBackend is what the sale event handler and scheduled jobs use to reprice products.
+// It is implemented in-process by LocalBackend and remotely by HTTPBackend.
+type PricingBackend interface {
+	// ComputeFeatures refreshes the pricing features of a product
+	ComputeFeatures(ctx context.Context, productId int) error
+	// AdjustPrice reprices a single product
+	AdjustPrice(ctx context.Context, productId int) error
+	// AdjustAll reprices every product and returns how many were repriced
+	AdjustAll(ctx context.Context) (int, error)
+	// Train fits a new model and returns its model version
+	Train(ctx context.Context) (string, error)
+}
+
+// Backend kinds accepted in PRICING_BACKEND
+const (
+	BackendLocal = "local"
+	BackendHTTP  = "http"
+)
+
+// BackendConfig holds the pricing backend configuration
+type BackendConfig struct {
+	Kind          string
+	BaseURL       string
+	Timeout       time.Duration
+	Review        ReviewConfig
+	ElasticityCap ElasticityCapConfig
+	Breaker       BreakerConfig
+	Repricer      RepricerConfig
+}
+
+// LoadBackendConfig loads the pricing backend configuration from environment variables
+func LoadBackendConfig() (*BackendConfig, error) {
+	log.Printf("Loading pricing backend config")
+	cfg := &BackendConfig{
+		Kind:     config.GetEnv("PRICING_BACKEND"),
+		BaseURL:  config.GetEnv("PRICING_API_URL"),
+		Timeout:  30 * time.Second,
+		Breaker:  BreakerConfig{Threshold: DefaultBreakerThreshold, Cooldown: DefaultBreakerCooldown},
+		Repricer: RepricerConfig{Window: DefaultRepriceWindow, Concurrency: DefaultRepriceConcurrency},
+	}
+	if cfg.Kind == "" {
+		cfg.Kind = BackendLocal
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:5872"
+	}
+	if timeout := config.GetEnv("PRICING_API_TIMEOUT_SECONDS"); timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid PRICING_API_TIMEOUT_SECONDS: %q", timeout)
+		}
+		cfg.Timeout = time.Duration(seconds) * time.Second
+	}
+	if reviewMode := config.GetEnv("PRICING_REVIEW_MODE"); reviewMode != "" {
+		enabled, err := strconv.ParseBool(reviewMode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PRICING_REVIEW_MODE: %q", reviewMode)
+		}
+		cfg.Review.Enabled = enabled
+	}
+	if autoApprove := config.GetEnv("PRICING_AUTO_APPROVE_PCT"); autoApprove != "" {
+		pct, err := strconv.ParseFloat(autoApprove, 64)
+		if err != nil || pct < 0 {
+			return nil, fmt.Errorf("invalid PRICING_AUTO_APPROVE_PCT: %q", autoApprove)
+		}
+		cfg.Review.AutoApprovePct = pct
+	}
+	if threshold := config.GetEnv("PRICING_ELASTICITY_THRESHOLD"); threshold != "" {
+		t, err := strconv.ParseFloat(threshold, 64)
+		if err != nil || t < 0 {
+			return nil, fmt.Errorf("invalid PRICING_ELASTICITY_THRESHOLD: %q", threshold)
+		}
+		cfg.ElasticityCap.Threshold = t
+	}
+	if maxIncrease := config.GetEnv("PRICING_ELASTIC_MAX_INCREASE_PCT"); maxIncrease != "" {
+		pct, err := strconv.ParseFloat(maxIncrease, 64)
+		if err != nil || pct < 0 {
+			return nil, fmt.Errorf("invalid PRICING_ELASTIC_MAX_INCREASE_PCT: %q", maxIncrease)
+		}
+		cfg.ElasticityCap.MaxIncreasePct = pct
+	}
+	if threshold := config.GetEnv("PRICING_BREAKER_THRESHOLD"); threshold != "" {
+		t, err := strconv.Atoi(threshold)
+		if err != nil || t < 0 {
+			return nil, fmt.Errorf("invalid PRICING_BREAKER_THRESHOLD: %q", threshold)
+		}
+		cfg.Breaker.Threshold = t
+	}
+	if cooldown := config.GetEnv("PRICING_BREAKER_COOLDOWN_SECONDS"); cooldown != "" {
+		seconds, err := strconv.Atoi(cooldown)
+		if err != nil || seconds <= 0 {
+			return nil, fmt.Errorf("invalid PRICING_BREAKER_COOLDOWN_SECONDS: %q", cooldown)
+		}
+		cfg.Breaker.Cooldown = time.Duration(seconds) * time.Second
+	}
+	if window := config.GetEnv("PRICING_REPRICE_WINDOW_MS"); window != "" {
+		ms, err := strconv.Atoi(window)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("invalid PRICING_REPRICE_WINDOW_MS: %q", window)
+		}
+		cfg.Repricer.Window = time.Duration(ms) * time.Millisecond
+	}
+	if concurrency := config.GetEnv("PRICING_REPRICE_CONCURRENCY"); concurrency != "" {
+		n, err := strconv.Atoi(concurrency)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid PRICING_REPRICE_CONCURRENCY: %q", concurrency)
+		}
+		cfg.Repricer.Concurrency = n
+	}
+	return cfg, nil
+}
+
+// NewBackend creates the pricing backend selected by the configuration. The HTTP backend is
+// put behind a circuit breaker unless the breaker is turned off; the in-process engine has no
+// service to be down.
+func NewBackend(cfg *BackendConfig, db *sqlx.DB) (PricingBackend, error) {
+	backend, err := newBackend(cfg, db)
+	if err != nil || cfg.Kind != BackendHTTP || cfg.Breaker.Threshold == 0 {
+		return backend, err
+	}
+	return NewCircuitBreaker(backend, cfg.Breaker), nil
+}
+
+func newBackend(cfg *BackendConfig, db *sqlx.DB) (PricingBackend, error) {
+	switch cfg.Kind {
+	case BackendLocal:
+		engine := NewEngine(db)
+		engine.Review = cfg.Review
+		engine.ElasticityCap = cfg.ElasticityCap
+		return NewLocalBackend(engine), nil
+	case BackendHTTP:
+		if cfg.Review.Enabled {
+			return nil, fmt.Errorf("PRICING_REVIEW_MODE requires the %s pricing backend", BackendLocal)
+		}
+		if cfg.ElasticityCap.enabled() {
+			return nil, fmt.Errorf("PRICING_ELASTICITY_THRESHOLD requires the %s pricing backend", BackendLocal)
+		}
+		return NewHTTPBackend(cfg.BaseURL, cfg.Timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown pricing backend: %q", cfg.Kind)
+	}
+}
+
+// LocalBackend runs the pricing engine in-process
+type LocalBackend struct {
+	engine *Engine
+}
+
+func NewLocalBackend(engine *Engine) *LocalBackend {
+	return &LocalBackend{engine: engine}
+}
+
+func (b *LocalBackend) ComputeFeatures(ctx context.Context, productId int) error {
+	_, err := b.engine.ComputeFeatures(ctx, productId)
+	return err
+}
+
+func (b *LocalBackend) AdjustPrice(ctx context.Context, productId int) error {
+	_, err := b.engine.AdjustPrice(ctx, productId)
+	return err
+}
+
+func (b *LocalBackend) AdjustAll(ctx context.Context) (int, error) {
+	adjustments, err := b.engine.AdjustAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(adjustments), nil
+}
+
+func (b *LocalBackend) Train(ctx context.Context) (string, error) {
+	coef, err := b.engine.Train(ctx)
+	if err != nil {
+		return "", err
+	}
+	return coef.ModelVersion, nil
+}
@@ -0,0 +1,204 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/lib/pq"
+)
+
+// MaxBacktestDays bounds the date range of a single backtest
+const MaxBacktestDays = 180
+
+// BacktestPoint is one day of a product's simulated price path
+type BacktestPoint struct {
+	Date           time.Time `json:"date"`
+	Ratio          float64   `json:"ratio"`
+	Clamped        bool      `json:"clamped"`
+	SimulatedPrice float64   `json:"simulated_price"`
+	ActualPrice    float64   `json:"actual_price"`
+	UnitsSold      int       `json:"units_sold"`
+}
+
+// ProductBacktest is the simulated price path and revenue of a single product
+type ProductBacktest struct {
+	ProductId        int             `json:"product_id"`
+	ProductName      string          `json:"product_name"`
+	BasePrice        float64         `json:"base_price"`
+	ClampedDays      int             `json:"clamped_days"`
+	ActualRevenue    float64         `json:"actual_revenue"`
+	SimulatedRevenue float64         `json:"simulated_revenue"`
+	RevenueDelta     float64         `json:"revenue_delta"`
+	Path             []BacktestPoint `json:"path"`
+}
+
+// BacktestResult summarises how a model would have priced products over a date range
+type BacktestResult struct {
+	ModelVersion     string            `json:"model_version"`
+	From             time.Time         `json:"from"`
+	To               time.Time         `json:"to"`
+	ProductCount     int               `json:"product_count"`
+	ClampedProducts  int               `json:"clamped_products"`
+	ClampedDays      int               `json:"clamped_days"`
+	ActualRevenue    float64           `json:"actual_revenue"`
+	SimulatedRevenue float64           `json:"simulated_revenue"`
+	RevenueDelta     float64           `json:"revenue_delta"`
+	Products         []ProductBacktest `json:"products"`
+}
+
+// historicalFeatures is a product's features as they stood at the end of a day
+type historicalFeatures struct {
+	rawFeatures
+	Day           time.Time `db:"day"`
+	ProductName   string    `db:"product_name"`
+	BasePrice     float64   `db:"base_price"`
+	ActualPrice   float64   `db:"actual_price"`
+	UnitsSold     int       `db:"units_sold"`
+	ActualRevenue float64   `db:"actual_revenue"`
+}
+
+// historicalFeaturesQuery rebuilds each product's features at the end of every day in [$1, $2]
+// from the timestamped sales, reviews, wishlist and stock history. Category percentiles are
+// ranked over every product, then the rows are filtered to the product ids in $3 (all when empty).
+// review_score is only the average rating; Backtest blends in the review sentiment.
+const historicalFeaturesQuery = `
+	WITH days AS (
+		SELECT generate_series($1::date, $2::date, interval '1 day')::date AS day
+	),
+	product_days AS (
+		SELECT d.day, p.id AS product_id, p.name AS product_name, p.category_id
+		FROM days d
+		CROSS JOIN products p
+	),
+	sales_to_date AS (
+		SELECT
+			pd.day, pd.product_id,
+			COUNT(s.id) AS total_sales_count,
+			COALESCE(SUM(s.sale_price * s.quantity), 0) AS total_sales_value,
+			COUNT(s.id) FILTER (WHERE s.created_at >= pd.day + 1 - ($4 || ' days')::interval) AS recent_sales_count,
+			COALESCE(SUM(s.quantity) FILTER (WHERE s.created_at >= pd.day), 0) AS units_sold,
+			COALESCE(SUM(s.sale_price * s.quantity) FILTER (WHERE s.created_at >= pd.day), 0) AS actual_revenue,
+			MAX(s.created_at) AS last_sale
+		FROM product_days pd
+		LEFT JOIN sales s ON s.product_id = pd.product_id AND s.created_at < pd.day + 1
+		GROUP BY pd.day, pd.product_id
+	),
+	history AS (
+		SELECT
+			pd.day, pd.product_id, pd.product_name, pm.base_price,
+			COALESCE(GREATEST(pd.day - DATE(st.last_sale), 0), 0) AS days_since_last_sale,
+			st.recent_sales_count, st.total_sales_count, st.total_sales_value,
+			PERCENT_RANK() OVER (PARTITION BY pd.day, pd.category_id ORDER BY st.total_sales_value) AS category_percentile,
+			COALESCE((
+				SELECT AVG(r.rating) FROM reviews r
+				WHERE r.product_id = pd.product_id AND r.created_at < pd.day + 1
+			), 0) AS review_score,
+			(
+				SELECT COUNT(*) FROM wishlist_items w
+				WHERE w.product_id = pd.product_id AND w.created_at < pd.day + 1
+			) AS wishlist_count,
+			COALESCE(GREATEST(pd.day - (
+				SELECT DATE(MAX(sh.created_at)) FROM stock_history sh
+				WHERE sh.product_id = pd.product_id AND sh.event_type = 'restock' AND sh.created_at < pd.day + 1
+			), 0), 0) AS days_since_restock,
+			COALESCE((
+				SELECT pa.new_price FROM price_adjustments pa
+				WHERE pa.product_id = pd.product_id AND pa.created_at < pd.day + 1 AND pa.price_type = 'adjusted'
+				ORDER BY pa.created_at DESC
+				LIMIT 1
+			), pm.adjusted_price, pm.base_price) AS actual_price,
+			st.units_sold, st.actual_revenue
+		FROM product_days pd
+		JOIN sales_to_date st ON st.day = pd.day AND st.product_id = pd.product_id
+		JOIN product_metrics pm ON pm.product_id = pd.product_id
+	)
+	SELECT *
+	FROM history
+	WHERE cardinality($3::int[]) = 0 OR product_id = ANY($3::int[])
+	ORDER BY product_id, day
+`
+
+// Backtest replays the sales history between from and to through a model and returns the
+// prices it would have set each day. Products are priced off their current base price, and
+// the simulated revenue assumes the units actually sold on each day.
+func (e *Engine) Backtest(ctx context.Context, coef *repo.PriceModelCoefficients, from, to time.Time, productIds []int) (*BacktestResult, error) {
+	from = from.Truncate(24 * time.Hour)
+	to = to.Truncate(24 * time.Hour)
+	if to.Before(from) {
+		return nil, errors.New("backtest range ends before it starts")
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > MaxBacktestDays {
+		return nil, fmt.Errorf("backtest range of %d days exceeds the %d day limit", days, MaxBacktestDays)
+	}
+	if productIds == nil {
+		productIds = []int{}
+	}
+
+	var rows []historicalFeatures
+	err := e.db.SelectContext(ctx, &rows, historicalFeaturesQuery, from, to, pq.Array(productIds), salesVelocityWindowDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical features: %w", err)
+	}
+	reviews, err := reviewTexts(ctx, e.db, productIds)
+	if err != nil {
+		return nil, err
+	}
+	sentiments := scoreReviews(reviews)
+
+	result := &BacktestResult{
+		ModelVersion: coef.ModelVersion,
+		From:         from,
+		To:           to,
+		Products:     []ProductBacktest{},
+	}
+	for i := range rows {
+		row := &rows[i]
+		if n := len(result.Products); n == 0 || result.Products[n-1].ProductId != row.ProductId {
+			result.Products = append(result.Products, ProductBacktest{
+				ProductId:   row.ProductId,
+				ProductName: row.ProductName,
+				BasePrice:   row.BasePrice,
+			})
+		}
+		product := &result.Products[len(result.Products)-1]
+
+		// Blend the day's average rating with the reviews written by the end of the day
+		row.ReviewScore = blendReviewScore(row.ReviewScore, sentiments[row.ProductId].before(row.Day.AddDate(0, 0, 1)))
+		adj := e.propose(coef, &productPricing{PricingFeatures: row.features(), BasePrice: row.BasePrice}, "")
+		product.Path = append(product.Path, BacktestPoint{
+			Date:           row.Day,
+			Ratio:          adj.Ratio,
+			Clamped:        adj.Clamped,
+			SimulatedPrice: adj.NewPrice,
+			ActualPrice:    row.ActualPrice,
+			UnitsSold:      row.UnitsSold,
+		})
+		if adj.Clamped {
+			product.ClampedDays++
+		}
+		product.ActualRevenue += row.ActualRevenue
+		product.SimulatedRevenue += adj.NewPrice * float64(row.UnitsSold)
+	}
+
+	for i := range result.Products {
+		product := &result.Products[i]
+		product.ActualRevenue = roundPrice(product.ActualRevenue)
+		product.SimulatedRevenue = roundPrice(product.SimulatedRevenue)
+		product.RevenueDelta = roundPrice(product.SimulatedRevenue - product.ActualRevenue)
+
+		result.ActualRevenue += product.ActualRevenue
+		result.SimulatedRevenue += product.SimulatedRevenue
+		result.ClampedDays += product.ClampedDays
+		if product.ClampedDays > 0 {
+			result.ClampedProducts++
+		}
+	}
+	result.ProductCount = len(result.Products)
+	result.ActualRevenue = roundPrice(result.ActualRevenue)
+	result.SimulatedRevenue = roundPrice(result.SimulatedRevenue)
+	result.RevenueDelta = roundPrice(result.SimulatedRevenue - result.ActualRevenue)
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+)
+
+const (
+	// DefaultBreakerThreshold is how many calls in a row must fail to open the breaker
+	DefaultBreakerThreshold = 5
+	// DefaultBreakerCooldown is how long an open breaker rejects calls before trying one
+	DefaultBreakerCooldown = time.Minute
+)
+
+// ErrCircuitOpen is returned, wrapped in a CircuitOpenError, for calls an open breaker rejects
+var ErrCircuitOpen = errors.New("pricing backend circuit is open")
+
+// CircuitOpenError is a call rejected by an open breaker, with how long until it tries again
+type CircuitOpenError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%v, retry in %s", ErrCircuitOpen, e.RetryAfter.Round(time.Second))
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// UnavailableError is a pricing backend that could not be reached or failed on its side, as
+// opposed to one that turned a request down. Only these count towards opening the breaker.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// BreakerConfig configures the circuit breaker around the pricing backend.
+// A zero Threshold turns the breaker off.
+type BreakerConfig struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// CircuitBreaker stops calling a pricing backend that keeps failing. After Threshold calls in a
+// row fail with an UnavailableError it rejects every call for Cooldown, then lets a single call
+// through: an answer closes the breaker, another UnavailableError opens it for another Cooldown.
+// Other errors, such as a product without pricing data, mean the backend is up.
+type CircuitBreaker struct {
+	backend  PricingBackend
+	config   BreakerConfig
+	mutex    sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(backend PricingBackend, config BreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{backend: backend, config: config}
+}
+
+// allow reports whether a call may go through, and whether it is the probe of an open breaker
+func (b *CircuitBreaker) allow() (probe bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.failures < b.config.Threshold {
+		return false, nil
+	}
+	if wait := b.config.Cooldown - time.Since(b.openedAt); wait > 0 {
+		return false, &CircuitOpenError{RetryAfter: wait}
+	}
+	if b.probing {
+		return false, &CircuitOpenError{RetryAfter: b.config.Cooldown}
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record updates the breaker with the outcome of a call
+func (b *CircuitBreaker) record(probe bool, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if probe {
+		b.probing = false
+	}
+	var unavailable *UnavailableError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// A call the caller gave up on says nothing about the backend
+		return
+	case !errors.As(err, &unavailable):
+		if b.failures >= b.config.Threshold {
+			logging.LogInfo("Pricing: backend recovered, closing circuit")
+		}
+		b.failures = 0
+	default:
+		b.failures++
+		if b.failures < b.config.Threshold {
+			return
+		}
+		if b.failures == b.config.Threshold || probe {
+			logging.LogError("Pricing: backend failed %d times in a row, opening circuit for %s: %v",
+				b.failures, b.config.Cooldown, err)
+		}
+		b.openedAt = time.Now()
+	}
+}
+
+// call runs fn unless the breaker is open
+func (b *CircuitBreaker) call(fn func() error) error {
+	probe, err := b.allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	b.record(probe, err)
+	return err
+}
+
+func (b *CircuitBreaker) ComputeFeatures(ctx context.Context, productId int) error {
+	return b.call(func() error { return b.backend.ComputeFeatures(ctx, productId) })
+}
+
+func (b *CircuitBreaker) AdjustPrice(ctx context.Context, productId int) error {
+	return b.call(func() error { return b.backend.AdjustPrice(ctx, productId) })
+}
+
+func (b *CircuitBreaker) AdjustAll(ctx context.Context) (int, error) {
+	var count int
+	err := b.call(func() (err error) {
+		count, err = b.backend.AdjustAll(ctx)
+		return err
+	})
+	return count, err
+}
+
+func (b *CircuitBreaker) Train(ctx context.Context) (string, error) {
+	var version string
+	err := b.call(func() (err error) {
+		version, err = b.backend.Train(ctx)
+		return err
+	})
+	return version, err
+}
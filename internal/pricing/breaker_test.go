@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerCountsOnlyUnavailableErrors(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	breaker := NewCircuitBreaker(fake, BreakerConfig{Threshold: 2, Cooldown: time.Minute})
+
+	// A backend that turns down products is up, however many it turns down
+	fake.AdjustPriceErr = errors.New("no pricing data found for product 1")
+	for i := 0; i < 5; i++ {
+		if err := breaker.AdjustPrice(ctx, 1); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("AdjustPrice() call %d = %v, want the backend error", i+1, err)
+		}
+	}
+
+	fake.AdjustPriceErr = &UnavailableError{Err: errors.New("connection refused")}
+	for i := 0; i < 2; i++ {
+		if err := breaker.AdjustPrice(ctx, 1); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("AdjustPrice() call %d = %v, want the backend error", i+1, err)
+		}
+	}
+
+	var circuitOpen *CircuitOpenError
+	if _, err := breaker.Train(ctx); !errors.As(err, &circuitOpen) {
+		t.Fatalf("Train() = %v, want a CircuitOpenError", err)
+	}
+	if fake.TrainCalls != 0 {
+		t.Errorf("TrainCalls = %d with the circuit open, want 0", fake.TrainCalls)
+	}
+}
+
+func TestCircuitBreakerProbe(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	breaker := NewCircuitBreaker(fake, BreakerConfig{Threshold: 1, Cooldown: time.Millisecond})
+
+	fake.ComputeFeaturesErr = &UnavailableError{Err: errors.New("502 Bad Gateway")}
+	_ = breaker.ComputeFeatures(ctx, 1)
+	time.Sleep(5 * time.Millisecond)
+
+	// The probe after the cooldown gets an answer, which closes the breaker
+	fake.ComputeFeaturesErr = nil
+	if err := breaker.ComputeFeatures(ctx, 1); err != nil {
+		t.Fatalf("probe ComputeFeatures() = %v, want nil", err)
+	}
+	if err := breaker.ComputeFeatures(ctx, 1); err != nil {
+		t.Errorf("ComputeFeatures() after recovery = %v, want nil", err)
+	}
+	if len(fake.ComputedProducts) != 3 {
+		t.Errorf("ComputedProducts = %v, want 3 calls", fake.ComputedProducts)
+	}
+}
+
+func TestCircuitBreakerIgnoresCallerDeadlines(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeBackend()
+	breaker := NewCircuitBreaker(fake, BreakerConfig{Threshold: 2, Cooldown: time.Minute})
+
+	// A deadline in between two failures must not reset the count
+	fake.AdjustPriceErr = &UnavailableError{Err: errors.New("connection refused")}
+	_ = breaker.AdjustPrice(ctx, 1)
+	fake.AdjustPriceErr = context.DeadlineExceeded
+	_ = breaker.AdjustPrice(ctx, 1)
+	fake.AdjustPriceErr = &UnavailableError{Err: errors.New("connection refused")}
+	_ = breaker.AdjustPrice(ctx, 1)
+
+	if err := breaker.AdjustPrice(ctx, 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("AdjustPrice() = %v, want the circuit open", err)
+	}
+}
@@ -0,0 +1,286 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// DefaultElasticityWindowDays is how much price and sales history an estimate reads
+	DefaultElasticityWindowDays = 90
+	// MinElasticityLevels is how many price levels with sales an estimate needs
+	MinElasticityLevels = 3
+)
+
+// ElasticityCapConfig limits price increases on highly elastic products.
+// A product whose elasticity is at or below -Threshold may rise by at most MaxIncreasePct
+// percent in one adjustment. A zero Threshold turns the cap off.
+type ElasticityCapConfig struct {
+	Threshold      float64
+	MaxIncreasePct float64
+}
+
+func (c ElasticityCapConfig) enabled() bool {
+	return c.Threshold > 0
+}
+
+// ElasticitySummary reports what an estimation run stored
+type ElasticitySummary struct {
+	WindowDays int `json:"window_days"`
+	Products   int `json:"products"`
+	Categories int `json:"categories"`
+}
+
+// priceLevel is the demand seen while a product sat at one price
+type priceLevel struct {
+	ProductId  int     `db:"product_id"`
+	CategoryId int     `db:"category_id"`
+	Price      float64 `db:"price"`
+	Days       int     `db:"days"`
+	Units      int     `db:"units"`
+}
+
+// priceLevelsQuery pairs the price in effect at the end of each of the last $1 days with the
+// units sold that day, then groups the days by price
+const priceLevelsQuery = `
+	WITH days AS (
+		SELECT generate_series(CURRENT_DATE - ($1::int - 1), CURRENT_DATE, interval '1 day')::date AS day
+	),
+	daily AS (
+		SELECT
+			p.id AS product_id, p.category_id,
+			COALESCE((
+				SELECT pa.new_price FROM price_adjustments pa
+				WHERE pa.product_id = p.id AND pa.created_at < d.day + 1 AND pa.price_type = 'adjusted'
+				ORDER BY pa.created_at DESC
+				LIMIT 1
+			), pm.base_price) AS price,
+			COALESCE((
+				SELECT SUM(s.quantity) FROM sales s
+				WHERE s.product_id = p.id AND s.created_at >= d.day AND s.created_at < d.day + 1
+			), 0) AS units
+		FROM products p
+		JOIN product_metrics pm ON pm.product_id = p.id
+		CROSS JOIN days d
+	)
+	SELECT product_id, category_id, price, COUNT(*) AS days, SUM(units) AS units
+	FROM daily
+	WHERE price > 0
+	GROUP BY product_id, category_id, price
+	ORDER BY product_id, price
+`
+
+// ElasticityEstimator estimates price elasticity of demand from price_adjustments and sales
+type ElasticityEstimator struct {
+	db *sqlx.DB
+}
+
+func NewElasticityEstimator(db *sqlx.DB) *ElasticityEstimator {
+	return &ElasticityEstimator{db: db}
+}
+
+// elasticityFit is a fitted log-log demand curve
+type elasticityFit struct {
+	elasticity   float64
+	rSquared     float64
+	observations int
+}
+
+// fitElasticity regresses log daily units on log price; the slope is the elasticity
+func fitElasticity(logPrices, logDemand []float64) (*elasticityFit, error) {
+	X := make([][]float64, len(logPrices))
+	for i, x := range logPrices {
+		X[i] = []float64{x}
+	}
+	model, metrics, err := FitOLS(X, logDemand)
+	if err != nil {
+		return nil, err
+	}
+	return &elasticityFit{
+		elasticity:   model.Weights[0],
+		rSquared:     metrics.RSquared,
+		observations: metrics.SampleSize,
+	}, nil
+}
+
+// Estimate recomputes every product and category elasticity over the last windowDays and
+// replaces the stored estimates. Price levels without sales are skipped, as their demand has
+// no logarithm. Each product needs MinElasticityLevels levels for its own estimate; the
+// category estimate pools the products with at least two levels, after removing each
+// product's mean so that only movement along its own demand curve counts.
+func (x *ElasticityEstimator) Estimate(ctx context.Context, windowDays int) (*ElasticitySummary, error) {
+	if windowDays <= 0 {
+		windowDays = DefaultElasticityWindowDays
+	}
+
+	var levels []priceLevel
+	if err := x.db.SelectContext(ctx, &levels, priceLevelsQuery, windowDays); err != nil {
+		return nil, fmt.Errorf("failed to get price levels: %w", err)
+	}
+
+	type series struct {
+		categoryId int
+		logPrices  []float64
+		logDemand  []float64
+	}
+	var products []*series
+	byProduct := map[int]*series{}
+	var productIds []int
+	for _, level := range levels {
+		if level.Units <= 0 || level.Days <= 0 {
+			continue
+		}
+		s, ok := byProduct[level.ProductId]
+		if !ok {
+			s = &series{categoryId: level.CategoryId}
+			byProduct[level.ProductId] = s
+			products = append(products, s)
+			productIds = append(productIds, level.ProductId)
+		}
+		s.logPrices = append(s.logPrices, math.Log(level.Price))
+		s.logDemand = append(s.logDemand, math.Log(float64(level.Units)/float64(level.Days)))
+	}
+
+	productFits := map[int]*elasticityFit{}
+	pooledPrices := map[int][]float64{}
+	pooledDemand := map[int][]float64{}
+	var categoryIds []int
+	for i, s := range products {
+		if len(s.logPrices) >= MinElasticityLevels {
+			fit, err := fitElasticity(s.logPrices, s.logDemand)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fit product %d: %w", productIds[i], err)
+			}
+			productFits[productIds[i]] = fit
+		}
+		if len(s.logPrices) < 2 {
+			continue
+		}
+
+		var priceMean, demandMean float64
+		for j := range s.logPrices {
+			priceMean += s.logPrices[j]
+			demandMean += s.logDemand[j]
+		}
+		priceMean /= float64(len(s.logPrices))
+		demandMean /= float64(len(s.logDemand))
+		if _, ok := pooledPrices[s.categoryId]; !ok {
+			categoryIds = append(categoryIds, s.categoryId)
+		}
+		for j := range s.logPrices {
+			pooledPrices[s.categoryId] = append(pooledPrices[s.categoryId], s.logPrices[j]-priceMean)
+			pooledDemand[s.categoryId] = append(pooledDemand[s.categoryId], s.logDemand[j]-demandMean)
+		}
+	}
+
+	categoryFits := map[int]*elasticityFit{}
+	for _, categoryId := range categoryIds {
+		if len(pooledPrices[categoryId]) < MinElasticityLevels {
+			continue
+		}
+		fit, err := fitElasticity(pooledPrices[categoryId], pooledDemand[categoryId])
+		if err != nil {
+			return nil, fmt.Errorf("failed to fit category %d: %w", categoryId, err)
+		}
+		categoryFits[categoryId] = fit
+	}
+
+	tx, err := x.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM price_elasticities`); err != nil {
+		return nil, fmt.Errorf("failed to clear price elasticities: %w", err)
+	}
+	insertQuery := `
+		INSERT INTO price_elasticities (scope, scope_id, elasticity, r_squared, observations, window_days)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	store := func(scope repo.ElasticityScope, scopeId int, fit *elasticityFit) error {
+		_, err := tx.ExecContext(ctx, insertQuery, scope, scopeId, roundElasticity(fit.elasticity),
+			roundElasticity(fit.rSquared), fit.observations, windowDays)
+		if err != nil {
+			return fmt.Errorf("failed to store %s %d elasticity: %w", scope, scopeId, err)
+		}
+		return nil
+	}
+	for _, productId := range productIds {
+		if fit, ok := productFits[productId]; ok {
+			if err = store(repo.ElasticityScopeProduct, productId, fit); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, categoryId := range categoryIds {
+		if fit, ok := categoryFits[categoryId]; ok {
+			if err = store(repo.ElasticityScopeCategory, categoryId, fit); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	summary := &ElasticitySummary{
+		WindowDays: windowDays,
+		Products:   len(productFits),
+		Categories: len(categoryFits),
+	}
+	logging.LogInfo("Pricing: estimated elasticity for %d products and %d categories over %d days",
+		summary.Products, summary.Categories, windowDays)
+	return summary, nil
+}
+
+// ForProduct returns the product's own elasticity, or its category's when it has none.
+// It returns nil when neither has been estimated.
+func (x *ElasticityEstimator) ForProduct(ctx context.Context, q sqlx.QueryerContext, productId int) (*repo.PriceElasticity, error) {
+	var elasticity repo.PriceElasticity
+	query := `
+		SELECT e.*
+		FROM products p
+		JOIN price_elasticities e
+			ON (e.scope = 'product' AND e.scope_id = p.id)
+			OR (e.scope = 'category' AND e.scope_id = p.category_id)
+		WHERE p.id = $1
+		ORDER BY (e.scope = 'product') DESC
+		LIMIT 1
+	`
+	err := sqlx.GetContext(ctx, q, &elasticity, query, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get price elasticity: %w", err)
+	}
+	return &elasticity, nil
+}
+
+// capIncrease holds back a price increase on a highly elastic product
+func (e *Engine) capIncrease(ctx context.Context, q sqlx.QueryerContext, adj *Adjustment) error {
+	if !e.ElasticityCap.enabled() || adj.NewPrice <= adj.OldPrice {
+		return nil
+	}
+	elasticity, err := e.elasticities.ForProduct(ctx, q, adj.ProductId)
+	if err != nil || elasticity == nil || elasticity.Elasticity > -e.ElasticityCap.Threshold {
+		return err
+	}
+	if limit := roundPrice(adj.OldPrice * (1 + e.ElasticityCap.MaxIncreasePct/100)); adj.NewPrice > limit {
+		adj.NewPrice = limit
+		adj.ElasticityCapped = true
+	}
+	return nil
+}
+
+func roundElasticity(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
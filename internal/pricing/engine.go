@@ -0,0 +1,494 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+// Adjusted prices are bounded to +-20% of the base price
+const (
+	DefaultMinRatio = 0.8
+	DefaultMaxRatio = 1.2
+)
+
+// DefaultTrainingWindowDays is how far back the scheduled training run reads feature snapshots
+const DefaultTrainingWindowDays = 90
+
+// Engine computes pricing features, trains the regression model and reprices products in-process
+type Engine struct {
+	db            *sqlx.DB
+	guard         *Guard
+	proposals     *ProposalQueue
+	registry      *Registry
+	elasticities  *ElasticityEstimator
+	MinRatio      float64
+	MaxRatio      float64
+	Review        ReviewConfig
+	ElasticityCap ElasticityCapConfig
+}
+
+func NewEngine(db *sqlx.DB) *Engine {
+	guard := NewGuard(db)
+	return &Engine{
+		db:           db,
+		guard:        guard,
+		proposals:    NewProposalQueue(db, guard),
+		registry:     NewRegistry(db),
+		elasticities: NewElasticityEstimator(db),
+		MinRatio:     DefaultMinRatio,
+		MaxRatio:     DefaultMaxRatio,
+	}
+}
+
+// Adjustment describes a single repricing decision
+type Adjustment struct {
+	ProductId        int     `json:"product_id"`
+	OldPrice         float64 `json:"old_price"`
+	NewPrice         float64 `json:"new_price"`
+	Ratio            float64 `json:"ratio"`
+	Clamped          bool    `json:"clamped"`
+	ElasticityCapped bool    `json:"elasticity_capped"`
+	ModelVersion     string  `json:"model_version"`
+	ConfidenceScore  float64 `json:"confidence_score"`
+	Source           string  `json:"source"`
+	Rejected         bool    `json:"rejected"`
+	Held             bool    `json:"held"`
+	Reason           string  `json:"reason,omitempty"`
+	Pending          bool    `json:"pending"`
+	ProposalId       int     `json:"proposal_id,omitempty"`
+}
+
+func (a Adjustment) priceChange() PriceChange {
+	return PriceChange{
+		ProductId:    a.ProductId,
+		PriceType:    repo.PriceTypeAdjusted,
+		OldPrice:     a.OldPrice,
+		NewPrice:     a.NewPrice,
+		Source:       a.Source,
+		ModelVersion: a.ModelVersion,
+	}
+}
+
+// productPricing is a product's stored features together with its current prices
+type productPricing struct {
+	repo.PricingFeatures
+	BasePrice     float64         `db:"base_price"`
+	AdjustedPrice sql.NullFloat64 `db:"adjusted_price"`
+}
+
+const productPricingColumns = `
+	pf.id, pf.product_id,
+	COALESCE(pf.days_since_last_sale, 0) AS days_since_last_sale,
+	COALESCE(pf.sales_velocity, 0) AS sales_velocity,
+	COALESCE(pf.total_sales_count, 0) AS total_sales_count,
+	COALESCE(pf.total_sales_value, 0) AS total_sales_value,
+	COALESCE(pf.category_percentile, 0) AS category_percentile,
+	COALESCE(pf.review_score, 0) AS review_score,
+	COALESCE(pf.wishlist_to_sales_ratio, 0) AS wishlist_to_sales_ratio,
+	COALESCE(pf.days_since_restock, 0) AS days_since_restock,
+	pm.base_price, pm.adjusted_price
+`
+
+// ModelConfidence scores a model between 0 and 1 by its R-squared on the training data
+func ModelConfidence(c *repo.PriceModelCoefficients) float64 {
+	return math.Round(math.Min(math.Max(c.RSquared, 0), 1)*10000) / 10000
+}
+
+// ModelFromCoefficients builds a predictive model from a stored coefficients row
+func ModelFromCoefficients(c *repo.PriceModelCoefficients) Model {
+	return Model{
+		Intercept: c.Intercept,
+		Weights: []float64{
+			c.DaysSinceLastSaleCoef,
+			c.SalesVelocityCoef,
+			c.TotalSalesCountCoef,
+			c.TotalSalesValueCoef,
+			c.CategoryPercentileCoef,
+			c.ReviewScoreCoef,
+			c.WishlistToSalesRatioCoef,
+			c.DaysSinceRestockCoef,
+		},
+	}
+}
+
+// propose computes the new adjusted price for a product without writing it
+func (e *Engine) propose(coef *repo.PriceModelCoefficients, p *productPricing, source string) Adjustment {
+	rawRatio := ModelFromCoefficients(coef).Predict(FeatureVector(&p.PricingFeatures))
+	ratio := math.Min(math.Max(rawRatio, e.MinRatio), e.MaxRatio)
+
+	oldPrice := p.BasePrice
+	if p.AdjustedPrice.Valid {
+		oldPrice = p.AdjustedPrice.Float64
+	}
+
+	return Adjustment{
+		ProductId:       p.ProductId,
+		OldPrice:        oldPrice,
+		NewPrice:        roundPrice(p.BasePrice * ratio),
+		Ratio:           ratio,
+		Clamped:         ratio != rawRatio,
+		ModelVersion:    coef.ModelVersion,
+		ConfidenceScore: ModelConfidence(coef),
+		Source:          source,
+	}
+}
+
+// commit caps increases on elastic products, runs the adjustment past the pricing policies,
+// then applies, queues or rejects it. Products with a temporary scheduled price are held.
+func (e *Engine) commit(ctx context.Context, tx *sqlx.Tx, adj *Adjustment) error {
+	held, err := heldBySchedule(ctx, tx, adj.ProductId)
+	if err != nil {
+		return err
+	}
+	if held {
+		adj.Held = true
+		adj.Reason = "a temporary scheduled price is in effect"
+		return nil
+	}
+	if err := e.capIncrease(ctx, tx, adj); err != nil {
+		return err
+	}
+	change := adj.priceChange()
+
+	err = e.guard.Check(ctx, tx, change)
+	var violation *PolicyViolation
+	if errors.As(err, &violation) {
+		adj.Rejected = true
+		adj.Reason = violation.Reason
+		return e.guard.Reject(ctx, tx, change, violation.Reason)
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.Review.needsReview(*adj) {
+		adj.Pending = true
+		adj.ProposalId, err = e.proposals.record(ctx, tx, *adj, repo.ProposalStatusPending)
+		return err
+	}
+	if err = applyAdjustment(ctx, tx, *adj); err != nil {
+		return err
+	}
+	if e.Review.Enabled {
+		adj.ProposalId, err = e.proposals.record(ctx, tx, *adj, repo.ProposalStatusAutoApproved)
+	}
+	return err
+}
+
+// commitAlone commits an adjustment in a transaction of its own
+func (e *Engine) commitAlone(ctx context.Context, adj *Adjustment) error {
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err = e.commit(ctx, tx, adj); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// applyAdjustment writes an adjustment to product_metrics and logs it in price_adjustments
+func applyAdjustment(ctx context.Context, tx *sqlx.Tx, adj Adjustment) error {
+	updateQuery := `
+		UPDATE product_metrics
+		SET adjusted_price = $1, last_price_update = NOW()
+		WHERE product_id = $2
+	`
+	if _, err := tx.ExecContext(ctx, updateQuery, adj.NewPrice, adj.ProductId); err != nil {
+		return fmt.Errorf("failed to update adjusted price: %w", err)
+	}
+
+	insertLogQuery := `
+		INSERT INTO price_adjustments (product_id, old_price, new_price, model_version, confidence_score, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := tx.ExecContext(ctx, insertLogQuery, adj.ProductId, adj.OldPrice, adj.NewPrice, adj.ModelVersion, adj.ConfidenceScore, adj.Source); err != nil {
+		return fmt.Errorf("failed to log price adjustment: %w", err)
+	}
+
+	markRunQuery := `
+		UPDATE pricing_features
+		SET last_model_run = NOW()
+		WHERE product_id = $1
+	`
+	if _, err := tx.ExecContext(ctx, markRunQuery, adj.ProductId); err != nil {
+		return fmt.Errorf("failed to update last model run: %w", err)
+	}
+	return nil
+}
+
+// shadowModel returns the shadow model, if any. Failures only skip the shadow evaluation.
+func (e *Engine) shadowModel(ctx context.Context) *repo.PriceModelCoefficients {
+	shadow, err := e.registry.Shadow(ctx)
+	if err != nil {
+		logging.LogError("Pricing: skipping shadow evaluation: %v", err)
+		return nil
+	}
+	return shadow
+}
+
+// evaluateShadow logs the prices the shadow model would have set next to the active model's
+func (e *Engine) evaluateShadow(ctx context.Context, shadow *repo.PriceModelCoefficients, products []productPricing, adjustments []Adjustment) {
+	for i := range adjustments {
+		shadowAdj := e.propose(shadow, &products[i], adjustments[i].Source)
+		if err := logShadow(ctx, e.db, adjustments[i], shadowAdj); err != nil {
+			logging.LogError("Pricing: %v", err)
+		}
+	}
+}
+
+// AdjustPrice reprices a single product with the active model
+func (e *Engine) AdjustPrice(ctx context.Context, productId int) (*Adjustment, error) {
+	coef, err := e.registry.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p productPricing
+	query := `
+		SELECT ` + productPricingColumns + `
+		FROM pricing_features pf
+		JOIN product_metrics pm ON pf.product_id = pm.product_id
+		WHERE pf.product_id = $1
+	`
+	err = e.db.GetContext(ctx, &p, query, productId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("no pricing data found for product %d", productId)
+		}
+		return nil, fmt.Errorf("failed to get pricing data: %w", err)
+	}
+
+	adj := e.propose(coef, &p, SourceSale)
+	if err = e.commitAlone(ctx, &adj); err != nil {
+		return nil, err
+	}
+
+	if shadow := e.shadowModel(ctx); shadow != nil {
+		e.evaluateShadow(ctx, shadow, []productPricing{p}, []Adjustment{adj})
+	}
+
+	if adj.Rejected {
+		return &adj, nil
+	}
+	if adj.Held {
+		logging.LogInfo("Pricing: held product %d at %.2f, %s", adj.ProductId, adj.OldPrice, adj.Reason)
+		return &adj, nil
+	}
+	if adj.Pending {
+		logging.LogInfo("Pricing: queued proposal %d for product %d from %.2f to %.2f for review",
+			adj.ProposalId, adj.ProductId, adj.OldPrice, adj.NewPrice)
+		return &adj, nil
+	}
+	logging.LogInfo("Pricing: adjusted product %d from %.2f to %.2f (ratio %.4f, model %s)",
+		adj.ProductId, adj.OldPrice, adj.NewPrice, adj.Ratio, adj.ModelVersion)
+	return &adj, nil
+}
+
+// AdjustAll reprices every product that has pricing features with the active model. Products
+// that fail are logged and left out; it only fails when none could be adjusted.
+func (e *Engine) AdjustAll(ctx context.Context) ([]Adjustment, error) {
+	coef, err := e.registry.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var products []productPricing
+	query := `
+		SELECT ` + productPricingColumns + `
+		FROM pricing_features pf
+		JOIN product_metrics pm ON pf.product_id = pm.product_id
+		ORDER BY pf.product_id
+	`
+	if err = e.db.SelectContext(ctx, &products, query); err != nil {
+		return nil, fmt.Errorf("failed to get pricing data: %w", err)
+	}
+	if len(products) == 0 {
+		return nil, errors.New("no product data found")
+	}
+
+	// Each product is committed on its own, so that one failing does not undo the others
+	adjustments := make([]Adjustment, 0, len(products))
+	adjusted := make([]productPricing, 0, len(products))
+	clamped, rejected, pending, held, failed := 0, 0, 0, 0, 0
+	var lastErr error
+	for i := range products {
+		adj := e.propose(coef, &products[i], SourceScheduled)
+		if err = e.commitAlone(ctx, &adj); err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			logging.LogError("Pricing: failed to adjust product %d: %v", adj.ProductId, err)
+			failed++
+			lastErr = fmt.Errorf("product %d: %w", adj.ProductId, err)
+			continue
+		}
+		if adj.Clamped {
+			clamped++
+		}
+		if adj.Rejected {
+			rejected++
+		}
+		if adj.Pending {
+			pending++
+		}
+		if adj.Held {
+			held++
+		}
+		adjustments = append(adjustments, adj)
+		adjusted = append(adjusted, products[i])
+	}
+	if failed == len(products) {
+		return nil, fmt.Errorf("failed to adjust any of %d products, last error: %w", failed, lastErr)
+	}
+
+	if shadow := e.shadowModel(ctx); shadow != nil {
+		e.evaluateShadow(ctx, shadow, adjusted, adjustments)
+	}
+
+	logging.LogInfo("Pricing: adjusted prices for %d products, %d bounded by the clamp, %d rejected by policy, %d queued for review, %d held by a price schedule, %d failed",
+		len(adjustments)-rejected-pending-held, clamped, rejected, pending, held, failed)
+	return adjustments, nil
+}
+
+// Train fits a new model on the feature snapshots of the last DefaultTrainingWindowDays days
+func (e *Engine) Train(ctx context.Context) (*repo.PriceModelCoefficients, error) {
+	to := time.Now()
+	return e.TrainWindow(ctx, to.AddDate(0, 0, -DefaultTrainingWindowDays), to)
+}
+
+// TrainingSet returns the feature snapshots a model trained on [from, to) learns from:
+// the last snapshot of each product on each day, where both prices are known
+func (e *Engine) TrainingSet(ctx context.Context, from, to time.Time) ([]repo.PricingFeatureSnapshot, error) {
+	var snapshots []repo.PricingFeatureSnapshot
+	query := `
+		SELECT DISTINCT ON (s.product_id, DATE(s.captured_at))
+			s.id, s.product_id,
+			COALESCE(s.days_since_last_sale, 0) AS days_since_last_sale,
+			COALESCE(s.sales_velocity, 0) AS sales_velocity,
+			COALESCE(s.total_sales_count, 0) AS total_sales_count,
+			COALESCE(s.total_sales_value, 0) AS total_sales_value,
+			COALESCE(s.category_percentile, 0) AS category_percentile,
+			COALESCE(s.review_score, 0) AS review_score,
+			COALESCE(s.wishlist_to_sales_ratio, 0) AS wishlist_to_sales_ratio,
+			COALESCE(s.days_since_restock, 0) AS days_since_restock,
+			s.base_price, s.adjusted_price, s.captured_at, s.created_at, s.updated_at
+		FROM pricing_feature_snapshots s
+		WHERE s.captured_at >= $1 AND s.captured_at < $2
+		AND s.adjusted_price IS NOT NULL AND s.base_price > 0
+		ORDER BY s.product_id, DATE(s.captured_at), s.captured_at DESC
+	`
+	if err := e.db.SelectContext(ctx, &snapshots, query, from, to); err != nil {
+		return nil, fmt.Errorf("failed to get training data: %w", err)
+	}
+	return snapshots, nil
+}
+
+// TrainWindow fits a new model on the feature snapshots captured in [from, to) and stores its
+// coefficients along with the window, so the training set can be rebuilt later.
+// Each product contributes its last snapshot of each day. The target is the adjusted price
+// as a ratio of the base price at the time of the snapshot.
+// The model is registered as a candidate, unless there is no active model yet.
+func (e *Engine) TrainWindow(ctx context.Context, from, to time.Time) (*repo.PriceModelCoefficients, error) {
+	if !from.Before(to) {
+		return nil, errors.New("training window ends before it starts")
+	}
+
+	snapshots, err := e.TrainingSet(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	X := make([][]float64, len(snapshots))
+	y := make([]float64, len(snapshots))
+	for i := range snapshots {
+		features := snapshotFeatures(&snapshots[i])
+		X[i] = FeatureVector(&features)
+		y[i] = *snapshots[i].AdjustedPrice / *snapshots[i].BasePrice
+	}
+
+	model, metrics, err := FitOLS(X, y)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	coef := &repo.PriceModelCoefficients{
+		ModelVersion:             modelVersion(now),
+		TrainingDate:             now,
+		SampleSize:               metrics.SampleSize,
+		TrainingWindowStart:      &from,
+		TrainingWindowEnd:        &to,
+		RSquared:                 metrics.RSquared,
+		MSE:                      metrics.MSE,
+		RMSE:                     metrics.RMSE,
+		MAE:                      metrics.MAE,
+		Intercept:                model.Intercept,
+		DaysSinceLastSaleCoef:    model.Weights[0],
+		SalesVelocityCoef:        model.Weights[1],
+		TotalSalesCountCoef:      model.Weights[2],
+		TotalSalesValueCoef:      model.Weights[3],
+		CategoryPercentileCoef:   model.Weights[4],
+		ReviewScoreCoef:          model.Weights[5],
+		WishlistToSalesRatioCoef: model.Weights[6],
+		DaysSinceRestockCoef:     model.Weights[7],
+		Status:                   repo.ModelStatusCandidate,
+	}
+	if _, err = e.registry.Active(ctx); errors.Is(err, ErrNoActiveModel) {
+		coef.Status = repo.ModelStatusActive
+		coef.PromotedAt = &now
+	}
+
+	insertQuery := `
+		INSERT INTO price_model_coefficients (
+			model_version, training_date, sample_size, training_window_start, training_window_end, r_squared, mse, rmse, mae, intercept,
+			days_since_last_sale_coef, sales_velocity_coef, total_sales_count_coef,
+			total_sales_value_coef, category_percentile_coef, review_score_coef,
+			wishlist_to_sales_ratio_coef, days_since_restock_coef, status, promoted_at
+		) VALUES (
+			:model_version, :training_date, :sample_size, :training_window_start, :training_window_end, :r_squared, :mse, :rmse, :mae, :intercept,
+			:days_since_last_sale_coef, :sales_velocity_coef, :total_sales_count_coef,
+			:total_sales_value_coef, :category_percentile_coef, :review_score_coef,
+			:wishlist_to_sales_ratio_coef, :days_since_restock_coef, :status, :promoted_at
+		)
+		RETURNING id, created_at, updated_at
+	`
+	rows, err := e.db.NamedQueryContext(ctx, insertQuery, coef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save model coefficients: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err = rows.Scan(&coef.Id, &coef.CreatedAt, &coef.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read saved model coefficients: %w", err)
+		}
+	}
+
+	logging.LogInfo("Pricing: trained %s model %s on %d samples (r2=%.4f, rmse=%.4f, mae=%.4f)",
+		coef.Status, coef.ModelVersion, coef.SampleSize, coef.RSquared, coef.RMSE, coef.MAE)
+	return coef, nil
+}
+
+// modelVersion names a model trained at t. Versions are unique, so they go down to the
+// microsecond for trainings started together, such as a manual run alongside the scheduled one.
+func modelVersion(t time.Time) string {
+	return "v" + t.Format("20060102_150405.000000")
+}
+
+// roundPrice rounds to the cent, matching the DECIMAL(10, 2) price columns
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
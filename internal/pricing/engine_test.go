@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+func TestProposeClampsRatio(t *testing.T) {
+	engine := &Engine{MinRatio: DefaultMinRatio, MaxRatio: DefaultMaxRatio}
+
+	tests := []struct {
+		name      string
+		intercept float64
+		adjusted  sql.NullFloat64
+		ratio     float64
+		newPrice  float64
+		oldPrice  float64
+		clamped   bool
+	}{
+		{name: "within bounds", intercept: 1.1, ratio: 1.1, newPrice: 110, oldPrice: 100},
+		{name: "clamped to the floor", intercept: 0.5, ratio: 0.8, newPrice: 80, oldPrice: 100, clamped: true},
+		{name: "clamped to the ceiling", intercept: 1.7, ratio: 1.2, newPrice: 120, oldPrice: 100, clamped: true},
+		{name: "at the ceiling", intercept: 1.2, ratio: 1.2, newPrice: 120, oldPrice: 100},
+		{
+			name:      "old price is the adjusted price",
+			intercept: 0.9,
+			adjusted:  sql.NullFloat64{Float64: 95, Valid: true},
+			ratio:     0.9,
+			newPrice:  90,
+			oldPrice:  95,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coef := &repo.PriceModelCoefficients{ModelVersion: "v1", Intercept: tt.intercept, RSquared: 0.75}
+			product := &productPricing{
+				PricingFeatures: repo.PricingFeatures{ProductId: 7},
+				BasePrice:       100,
+				AdjustedPrice:   tt.adjusted,
+			}
+
+			adj := engine.propose(coef, product, SourceSale)
+			if !approxEqual(adj.Ratio, tt.ratio) {
+				t.Errorf("Ratio = %v, want %v", adj.Ratio, tt.ratio)
+			}
+			if adj.NewPrice != tt.newPrice {
+				t.Errorf("NewPrice = %v, want %v", adj.NewPrice, tt.newPrice)
+			}
+			if adj.OldPrice != tt.oldPrice {
+				t.Errorf("OldPrice = %v, want %v", adj.OldPrice, tt.oldPrice)
+			}
+			if adj.Clamped != tt.clamped {
+				t.Errorf("Clamped = %v, want %v", adj.Clamped, tt.clamped)
+			}
+			if adj.ProductId != 7 || adj.ModelVersion != "v1" || adj.Source != SourceSale || adj.ConfidenceScore != 0.75 {
+				t.Errorf("propose() = %+v, want product 7, model v1, source sale and confidence 0.75", adj)
+			}
+		})
+	}
+}
+
+func TestModelVersionIsUniqueWithinASecond(t *testing.T) {
+	trainedAt := time.Date(2025, 6, 4, 9, 30, 15, 0, time.UTC)
+
+	first := modelVersion(trainedAt)
+	second := modelVersion(trainedAt.Add(time.Millisecond))
+	if first == second {
+		t.Errorf("modelVersion() = %q for trainings a millisecond apart, want distinct versions", first)
+	}
+	if first != "v20250604_093015.000000" {
+		t.Errorf("modelVersion() = %q, want v20250604_093015.000000", first)
+	}
+}
@@ -0,0 +1,185 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrExperimentNotFound = errors.New("price experiment not found")
+	ErrExperimentStopped  = errors.New("price experiment is already stopped")
+)
+
+// Bucket assigns a customer to an experiment arm. The assignment is stable for a
+// customer within an experiment and independent across experiments.
+func Bucket(experimentId, customerId int, treatmentPct float64) repo.ExperimentArm {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%d", experimentId, customerId)
+	if float64(h.Sum32()%10000)/100 < treatmentPct {
+		return repo.ExperimentArmTreatment
+	}
+	return repo.ExperimentArmControl
+}
+
+// Variant is the price a customer pays for a product that is under experiment
+type Variant struct {
+	ExperimentId int                `json:"experiment_id"`
+	Arm          repo.ExperimentArm `json:"arm"`
+	Price        float64            `json:"price"`
+}
+
+// experimentProduct is a product in a running experiment together with its live prices
+type experimentProduct struct {
+	ExperimentId    int                    `db:"experiment_id"`
+	VariantType     repo.ExperimentVariant `db:"variant_type"`
+	PriceMultiplier sql.NullFloat64        `db:"price_multiplier"`
+	ModelVersion    sql.NullString         `db:"model_version"`
+	TreatmentPct    float64                `db:"treatment_pct"`
+	ProductId       int                    `db:"product_id"`
+	BasePrice       float64                `db:"base_price"`
+	AdjustedPrice   sql.NullFloat64        `db:"adjusted_price"`
+}
+
+// Experiments resolves the price each customer sees for products under a running experiment
+type Experiments struct {
+	db       *sqlx.DB
+	registry *Registry
+	guard    *Guard
+}
+
+func NewExperiments(db *sqlx.DB) *Experiments {
+	return &Experiments{db: db, registry: NewRegistry(db), guard: NewGuard(db)}
+}
+
+// Resolve returns the variant for each of productIds that is in a running experiment.
+// Products that are not under experiment are left out and sell at the live price.
+// The control arm keeps the live price; the treatment arm gets the experiment's price,
+// clamped to the product's policy.
+func (x *Experiments) Resolve(ctx context.Context, q sqlx.QueryerContext, customerId int, productIds []int) (map[int]Variant, error) {
+	variants := map[int]Variant{}
+	if len(productIds) == 0 {
+		return variants, nil
+	}
+
+	var products []experimentProduct
+	query := `
+		SELECT
+			e.id AS experiment_id, e.variant_type, e.price_multiplier, e.model_version, e.treatment_pct,
+			ep.product_id, pm.base_price, pm.adjusted_price
+		FROM price_experiment_products ep
+		JOIN price_experiments e ON e.id = ep.experiment_id
+		JOIN product_metrics pm ON pm.product_id = ep.product_id
+		WHERE e.status = 'running' AND ep.product_id = ANY($1::int[])
+		ORDER BY ep.product_id, e.id
+	`
+	if err := sqlx.SelectContext(ctx, q, &products, query, pq.Array(productIds)); err != nil {
+		return nil, fmt.Errorf("failed to get running experiments: %w", err)
+	}
+
+	for _, p := range products {
+		if _, ok := variants[p.ProductId]; ok {
+			continue
+		}
+		livePrice := p.BasePrice
+		if p.AdjustedPrice.Valid {
+			livePrice = p.AdjustedPrice.Float64
+		}
+
+		variant := Variant{
+			ExperimentId: p.ExperimentId,
+			Arm:          Bucket(p.ExperimentId, customerId, p.TreatmentPct),
+			Price:        livePrice,
+		}
+		if variant.Arm == repo.ExperimentArmTreatment {
+			price, err := x.treatmentPrice(ctx, q, p, livePrice)
+			if err != nil {
+				return nil, err
+			}
+			variant.Price = price
+		}
+		variants[p.ProductId] = variant
+	}
+	return variants, nil
+}
+
+// treatmentPrice prices a product for the treatment arm of its experiment
+func (x *Experiments) treatmentPrice(ctx context.Context, q sqlx.QueryerContext, p experimentProduct, livePrice float64) (float64, error) {
+	price := livePrice
+	switch p.VariantType {
+	case repo.ExperimentVariantMultiplier:
+		price = roundPrice(livePrice * p.PriceMultiplier.Float64)
+	case repo.ExperimentVariantModel:
+		coef, err := x.registry.Get(ctx, p.ModelVersion.String)
+		if err != nil {
+			return 0, err
+		}
+		var current productPricing
+		query := `
+			SELECT ` + productPricingColumns + `
+			FROM pricing_features pf
+			JOIN product_metrics pm ON pf.product_id = pm.product_id
+			WHERE pf.product_id = $1
+		`
+		err = sqlx.GetContext(ctx, q, &current, query, p.ProductId)
+		if errors.Is(err, sql.ErrNoRows) {
+			// No features yet, so the model has nothing to price with
+			return livePrice, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get pricing data: %w", err)
+		}
+		ratio := ModelFromCoefficients(coef).Predict(FeatureVector(&current.PricingFeatures))
+		ratio = math.Min(math.Max(ratio, DefaultMinRatio), DefaultMaxRatio)
+		price = roundPrice(p.BasePrice * ratio)
+	}
+
+	// The treatment price is held to the product's policy like any other price change from the
+	// live price. When the limits leave no price that passes, the treatment arm pays the live price.
+	policy, err := x.guard.EffectivePolicy(ctx, q, p.ProductId)
+	if err != nil {
+		return 0, err
+	}
+	change := PriceChange{
+		ProductId:    p.ProductId,
+		PriceType:    repo.PriceTypeAdjusted,
+		OldPrice:     livePrice,
+		NewPrice:     policy.Clamp(livePrice, price),
+		ModelVersion: p.ModelVersion.String,
+	}
+	if err = policy.Evaluate(change, livePrice); err != nil {
+		logging.LogInfo("Pricing: experiment %d keeps the live price %.2f for product %d: %v",
+			p.ExperimentId, livePrice, p.ProductId, err)
+		return livePrice, nil
+	}
+	return change.NewPrice, nil
+}
+
+// Expose records that a customer has been shown the prices of the experiments in variants.
+// Only the first exposure per experiment is kept.
+func (x *Experiments) Expose(ctx context.Context, e sqlx.ExecerContext, customerId int, variants map[int]Variant) error {
+	exposed := map[int]bool{}
+	query := `
+		INSERT INTO price_experiment_exposures (experiment_id, customer_id, arm)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (experiment_id, customer_id) DO NOTHING
+	`
+	for _, variant := range variants {
+		if exposed[variant.ExperimentId] {
+			continue
+		}
+		exposed[variant.ExperimentId] = true
+		if _, err := e.ExecContext(ctx, query, variant.ExperimentId, customerId, variant.Arm); err != nil {
+			return fmt.Errorf("failed to record experiment exposure: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+// ErrNoPricingData is returned when a product has no pricing features to explain
+var ErrNoPricingData = errors.New("no pricing data found for product")
+
+// Clamp outcomes reported by a price explanation
+const (
+	ClampNone    = "none"
+	ClampFloor   = "floor"
+	ClampCeiling = "ceiling"
+)
+
+// FeatureContribution is how much one feature moved the price ratio: Coefficient × Value
+type FeatureContribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Coefficient  float64 `json:"coefficient"`
+	Contribution float64 `json:"contribution"`
+}
+
+// PriceExplanation breaks a product's price down into the active model's terms.
+// The ratio is Intercept plus the sum of the contributions, clamped to [MinRatio, MaxRatio],
+// and the model price is the base price times the ratio.
+type PriceExplanation struct {
+	ProductId         int                   `json:"product_id"`
+	ModelVersion      string                `json:"model_version"`
+	Intercept         float64               `json:"intercept"`
+	Contributions     []FeatureContribution `json:"contributions"`
+	RawRatio          float64               `json:"raw_ratio"`
+	Ratio             float64               `json:"ratio"`
+	MinRatio          float64               `json:"min_ratio"`
+	MaxRatio          float64               `json:"max_ratio"`
+	Clamp             string                `json:"clamp"`
+	BasePrice         float64               `json:"base_price"`
+	ModelPrice        float64               `json:"model_price"`
+	CurrentPrice      float64               `json:"current_price"`
+	FeaturesUpdatedAt time.Time             `json:"features_updated_at"`
+	LastAdjustment    *repo.PriceAdjustment `json:"last_adjustment"`
+}
+
+// Explain breaks the product's price down into per-feature contributions from the active
+// model and its current pricing features, along with the clamp and the latest logged change.
+// The current price can differ from the model price when a policy, review, schedule or an
+// older model set it; LastAdjustment shows what did.
+func (e *Engine) Explain(ctx context.Context, productId int) (*PriceExplanation, error) {
+	coef, err := e.registry.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var p productPricing
+	query := `
+		SELECT ` + productPricingColumns + `, pf.updated_at
+		FROM pricing_features pf
+		JOIN product_metrics pm ON pf.product_id = pm.product_id
+		WHERE pf.product_id = $1
+	`
+	if err = e.db.GetContext(ctx, &p, query, productId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoPricingData
+		}
+		return nil, fmt.Errorf("failed to get pricing data: %w", err)
+	}
+
+	model := ModelFromCoefficients(coef)
+	values := FeatureVector(&p.PricingFeatures)
+	explanation := &PriceExplanation{
+		ProductId:         productId,
+		ModelVersion:      coef.ModelVersion,
+		Intercept:         model.Intercept,
+		Contributions:     make([]FeatureContribution, len(values)),
+		RawRatio:          model.Predict(values),
+		MinRatio:          e.MinRatio,
+		MaxRatio:          e.MaxRatio,
+		Clamp:             ClampNone,
+		BasePrice:         p.BasePrice,
+		CurrentPrice:      p.BasePrice,
+		FeaturesUpdatedAt: p.UpdatedAt,
+	}
+	for i, value := range values {
+		explanation.Contributions[i] = FeatureContribution{
+			Feature:      FeatureNames[i],
+			Value:        value,
+			Coefficient:  model.Weights[i],
+			Contribution: model.Weights[i] * value,
+		}
+	}
+	sort.SliceStable(explanation.Contributions, func(i, j int) bool {
+		return math.Abs(explanation.Contributions[i].Contribution) > math.Abs(explanation.Contributions[j].Contribution)
+	})
+
+	explanation.Ratio = math.Min(math.Max(explanation.RawRatio, e.MinRatio), e.MaxRatio)
+	switch {
+	case explanation.RawRatio < e.MinRatio:
+		explanation.Clamp = ClampFloor
+	case explanation.RawRatio > e.MaxRatio:
+		explanation.Clamp = ClampCeiling
+	}
+	explanation.ModelPrice = roundPrice(p.BasePrice * explanation.Ratio)
+	if p.AdjustedPrice.Valid {
+		explanation.CurrentPrice = p.AdjustedPrice.Float64
+	}
+
+	var last repo.PriceAdjustment
+	lastQuery := `
+		SELECT *
+		FROM price_adjustments
+		WHERE product_id = $1 AND price_type = 'adjusted'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	err = e.db.GetContext(ctx, &last, lastQuery, productId)
+	if err == nil {
+		explanation.LastAdjustment = &last
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get last price adjustment: %w", err)
+	}
+	return explanation, nil
+}
+
+// PriceExplanationDetail adds what else shapes a product's price to its explanation:
+// the policy limits, the estimated elasticity and whether a schedule holds it
+type PriceExplanationDetail struct {
+	*PriceExplanation
+	Policy         *EffectivePolicy      `json:"policy"`
+	Elasticity     *repo.PriceElasticity `json:"elasticity"`
+	HeldBySchedule bool                  `json:"held_by_schedule"`
+}
+
+// ExplainDetail explains a product's price along with the policy, elasticity and schedule
+// state that can keep it from the model price
+func (e *Engine) ExplainDetail(ctx context.Context, productId int) (*PriceExplanationDetail, error) {
+	explanation, err := e.Explain(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	detail := &PriceExplanationDetail{PriceExplanation: explanation}
+	if detail.Policy, err = e.guard.EffectivePolicy(ctx, e.db, productId); err != nil {
+		return nil, err
+	}
+	if detail.Elasticity, err = e.elasticities.ForProduct(ctx, e.db, productId); err != nil {
+		return nil, err
+	}
+	if detail.HeldBySchedule, err = heldBySchedule(ctx, e.db, productId); err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
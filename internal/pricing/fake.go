@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"context"
+	"sync"
+)
+
+// FakeBackend is an in-memory PricingBackend for tests.
+// It records every call and returns the configured errors.
+type FakeBackend struct {
+	mutex sync.Mutex
+
+	ComputeFeaturesErr error
+	AdjustPriceErr     error
+	AdjustAllErr       error
+	TrainErr           error
+
+	// AdjustAllCount is returned by AdjustAll, ModelVersion by Train
+	AdjustAllCount int
+	ModelVersion   string
+
+	ComputedProducts []int
+	AdjustedProducts []int
+	AdjustAllCalls   int
+	TrainCalls       int
+}
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{ModelVersion: "fake"}
+}
+
+func (f *FakeBackend) ComputeFeatures(ctx context.Context, productId int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.ComputedProducts = append(f.ComputedProducts, productId)
+	return f.ComputeFeaturesErr
+}
+
+func (f *FakeBackend) AdjustPrice(ctx context.Context, productId int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.AdjustedProducts = append(f.AdjustedProducts, productId)
+	return f.AdjustPriceErr
+}
+
+func (f *FakeBackend) AdjustAll(ctx context.Context) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.AdjustAllCalls++
+	if f.AdjustAllErr != nil {
+		return 0, f.AdjustAllErr
+	}
+	return f.AdjustAllCount, nil
+}
+
+func (f *FakeBackend) Train(ctx context.Context) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.TrainCalls++
+	if f.TrainErr != nil {
+		return "", f.TrainErr
+	}
+	return f.ModelVersion, nil
+}
@@ -0,0 +1,222 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+)
+
+// salesVelocityWindowDays is the look-back window for sales velocity (sales per day)
+const salesVelocityWindowDays = 30
+
+// FeatureNames lists the pricing_features columns in the order the model uses them
+var FeatureNames = []string{
+	"days_since_last_sale",
+	"sales_velocity",
+	"total_sales_count",
+	"total_sales_value",
+	"category_percentile",
+	"review_score",
+	"wishlist_to_sales_ratio",
+	"days_since_restock",
+}
+
+// FeatureVector returns the features in FeatureNames order
+func FeatureVector(f *repo.PricingFeatures) []float64 {
+	return []float64{
+		float64(f.DaysSinceLastSale),
+		f.SalesVelocity,
+		float64(f.TotalSalesCount),
+		f.TotalSalesValue,
+		f.CategoryPercentile,
+		f.ReviewScore,
+		f.WishlistToSalesRatio,
+		float64(f.DaysSinceRestock),
+	}
+}
+
+// snapshotFeatures returns the features recorded in a snapshot
+func snapshotFeatures(s *repo.PricingFeatureSnapshot) repo.PricingFeatures {
+	return repo.PricingFeatures{
+		ProductId:            s.ProductId,
+		DaysSinceLastSale:    s.DaysSinceLastSale,
+		SalesVelocity:        s.SalesVelocity,
+		TotalSalesCount:      s.TotalSalesCount,
+		TotalSalesValue:      s.TotalSalesValue,
+		CategoryPercentile:   s.CategoryPercentile,
+		ReviewScore:          s.ReviewScore,
+		WishlistToSalesRatio: s.WishlistToSalesRatio,
+		DaysSinceRestock:     s.DaysSinceRestock,
+	}
+}
+
+// rawFeatures holds the aggregates the pricing features are derived from
+type rawFeatures struct {
+	ProductId          int     `db:"product_id"`
+	DaysSinceLastSale  int     `db:"days_since_last_sale"`
+	RecentSalesCount   int     `db:"recent_sales_count"`
+	TotalSalesCount    int     `db:"total_sales_count"`
+	TotalSalesValue    float64 `db:"total_sales_value"`
+	CategoryPercentile float64 `db:"category_percentile"`
+	ReviewScore        float64 `db:"review_score"`
+	WishlistCount      int     `db:"wishlist_count"`
+	DaysSinceRestock   int     `db:"days_since_restock"`
+}
+
+func (raw *rawFeatures) features() repo.PricingFeatures {
+	features := repo.PricingFeatures{
+		ProductId:          raw.ProductId,
+		DaysSinceLastSale:  raw.DaysSinceLastSale,
+		SalesVelocity:      float64(raw.RecentSalesCount) / salesVelocityWindowDays,
+		TotalSalesCount:    raw.TotalSalesCount,
+		TotalSalesValue:    raw.TotalSalesValue,
+		CategoryPercentile: raw.CategoryPercentile,
+		ReviewScore:        raw.ReviewScore,
+		DaysSinceRestock:   raw.DaysSinceRestock,
+	}
+	if raw.TotalSalesCount > 0 {
+		features.WishlistToSalesRatio = float64(raw.WishlistCount) / float64(raw.TotalSalesCount)
+	}
+	return features
+}
+
+// ComputeFeatures recomputes the pricing features for a product, upserts them into pricing_features
+// and appends them, with the product's current prices, to pricing_feature_snapshots.
+// review_score blends the product's average rating with the VADER sentiment of its reviews, as
+// the regression sidecar does.
+func (e *Engine) ComputeFeatures(ctx context.Context, productId int) (*repo.PricingFeatures, error) {
+	logging.LogInfo("Pricing: computing features for product %d", productId)
+
+	var raw rawFeatures
+	query := `
+		WITH category_rankings AS (
+			SELECT
+				p.id,
+				PERCENT_RANK() OVER (
+					PARTITION BY p.category_id
+					ORDER BY COALESCE(SUM(s.sale_price * s.quantity), 0)
+				) AS sales_percentile
+			FROM products p
+			LEFT JOIN sales s ON p.id = s.product_id
+			GROUP BY p.id, p.category_id
+		)
+		SELECT
+			p.id AS product_id,
+			COALESCE(GREATEST(CURRENT_DATE - DATE(pm.last_sale), 0), 0) AS days_since_last_sale,
+			(SELECT COUNT(*) FROM sales s
+				WHERE s.product_id = p.id
+				AND s.created_at >= NOW() - ($2 || ' days')::interval) AS recent_sales_count,
+			(SELECT COUNT(*) FROM sales s WHERE s.product_id = p.id) AS total_sales_count,
+			(SELECT COALESCE(SUM(s.sale_price * s.quantity), 0) FROM sales s WHERE s.product_id = p.id) AS total_sales_value,
+			COALESCE(cr.sales_percentile, 0) AS category_percentile,
+			COALESCE(pm.average_rating, 0) AS review_score,
+			COALESCE(pm.wishlist_count, 0) AS wishlist_count,
+			COALESCE(GREATEST(CURRENT_DATE - (
+				SELECT DATE(sh.created_at)
+				FROM stock_history sh
+				WHERE sh.product_id = p.id AND sh.event_type = 'restock'
+				ORDER BY sh.created_at DESC
+				LIMIT 1
+			), 0), 0) AS days_since_restock
+		FROM products p
+		LEFT JOIN product_metrics pm ON p.id = pm.product_id
+		LEFT JOIN category_rankings cr ON p.id = cr.id
+		WHERE p.id = $1
+	`
+	err := e.db.GetContext(ctx, &raw, query, productId, salesVelocityWindowDays)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("product not found")
+		}
+		logging.LogError("Pricing: failed to compute features for product %d: %v", productId, err)
+		return nil, fmt.Errorf("failed to compute features: %w", err)
+	}
+
+	// Blend the average rating with the sentiment of the review texts
+	reviews, err := reviewTexts(ctx, e.db, []int{productId})
+	if err != nil {
+		logging.LogError("Pricing: failed to compute features for product %d: %v", productId, err)
+		return nil, err
+	}
+	sentiments := make([]float64, len(reviews))
+	for i, review := range reviews {
+		sentiments[i] = reviewSentiment(review.ReviewText)
+	}
+	raw.ReviewScore = blendReviewScore(raw.ReviewScore, sentiments)
+
+	computed := raw.features()
+	features := &computed
+
+	upsertQuery := `
+		INSERT INTO pricing_features (
+			product_id, days_since_last_sale, sales_velocity, total_sales_count,
+			total_sales_value, category_percentile, review_score,
+			wishlist_to_sales_ratio, days_since_restock
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (product_id) DO UPDATE SET
+			days_since_last_sale = EXCLUDED.days_since_last_sale,
+			sales_velocity = EXCLUDED.sales_velocity,
+			total_sales_count = EXCLUDED.total_sales_count,
+			total_sales_value = EXCLUDED.total_sales_value,
+			category_percentile = EXCLUDED.category_percentile,
+			review_score = EXCLUDED.review_score,
+			wishlist_to_sales_ratio = EXCLUDED.wishlist_to_sales_ratio,
+			days_since_restock = EXCLUDED.days_since_restock
+		RETURNING id
+	`
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(
+		ctx,
+		upsertQuery,
+		features.ProductId,
+		features.DaysSinceLastSale,
+		features.SalesVelocity,
+		features.TotalSalesCount,
+		// total_sales_value is stored as an INTEGER column
+		int(math.Round(features.TotalSalesValue)),
+		features.CategoryPercentile,
+		features.ReviewScore,
+		features.WishlistToSalesRatio,
+		features.DaysSinceRestock,
+	).Scan(&features.Id)
+	if err != nil {
+		logging.LogError("Pricing: failed to save features for product %d: %v", productId, err)
+		return nil, fmt.Errorf("failed to save features: %w", err)
+	}
+
+	snapshotQuery := `
+		INSERT INTO pricing_feature_snapshots (
+			product_id, days_since_last_sale, sales_velocity, total_sales_count,
+			total_sales_value, category_percentile, review_score,
+			wishlist_to_sales_ratio, days_since_restock, base_price, adjusted_price
+		)
+		SELECT
+			pf.product_id, pf.days_since_last_sale, pf.sales_velocity, pf.total_sales_count,
+			pf.total_sales_value, pf.category_percentile, pf.review_score,
+			pf.wishlist_to_sales_ratio, pf.days_since_restock, pm.base_price, pm.adjusted_price
+		FROM pricing_features pf
+		LEFT JOIN product_metrics pm ON pm.product_id = pf.product_id
+		WHERE pf.product_id = $1
+	`
+	if _, err = tx.ExecContext(ctx, snapshotQuery, productId); err != nil {
+		logging.LogError("Pricing: failed to snapshot features for product %d: %v", productId, err)
+		return nil, fmt.Errorf("failed to snapshot features: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: features for product %d: %+v", productId, *features)
+	return features, nil
+}
@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxErrorBodyBytes caps how much of an error response is quoted back in the error
+const maxErrorBodyBytes = 512
+
+// HTTPBackend calls the Python regression service over HTTP.
+// The service writes prices and features itself and uses its newest model, so pricing
+// policies, the model registry, feature snapshots and the elasticity cap do not apply to this backend.
+type HTTPBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPBackend(baseURL string, timeout time.Duration) *HTTPBackend {
+	return &HTTPBackend{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// post sends a JSON request and decodes a 200 response into out (when out is not nil).
+// Transport errors and 5xx responses are returned as an UnavailableError.
+func (b *HTTPBackend) post(ctx context.Context, path string, payload interface{}, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonData, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request data: %w", err)
+		}
+		body = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to call %s: %w", path, err)
+		if ctx.Err() != nil {
+			return err
+		}
+		return &UnavailableError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		err := fmt.Errorf("%s returned %s: %s", path, resp.Status, strings.TrimSpace(string(snippet)))
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return &UnavailableError{Err: err}
+		}
+		return err
+	}
+
+	if out == nil {
+		// Drain so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+func (b *HTTPBackend) ComputeFeatures(ctx context.Context, productId int) error {
+	var resp struct {
+		Result string `json:"result"`
+	}
+	err := b.post(ctx, "/compute_features", map[string]int{"product_id": productId}, &resp)
+	if err != nil {
+		return err
+	}
+	// The service reports feature failures in a 200 response
+	if strings.HasPrefix(resp.Result, "error") {
+		return fmt.Errorf("compute features failed for product %d: %s", productId, resp.Result)
+	}
+	return nil
+}
+
+func (b *HTTPBackend) AdjustPrice(ctx context.Context, productId int) error {
+	return b.post(ctx, fmt.Sprintf("/adjust-price/%d", productId), nil, nil)
+}
+
+func (b *HTTPBackend) AdjustAll(ctx context.Context) (int, error) {
+	var resp struct {
+		Result []json.RawMessage `json:"result"`
+	}
+	if err := b.post(ctx, "/adjust-prices", nil, &resp); err != nil {
+		return 0, err
+	}
+	return len(resp.Result), nil
+}
+
+func (b *HTTPBackend) Train(ctx context.Context) (string, error) {
+	var resp struct {
+		ModelVersion string `json:"model_version"`
+	}
+	if err := b.post(ctx, "/train-model", nil, &resp); err != nil {
+		return "", err
+	}
+	return resp.ModelVersion, nil
+}
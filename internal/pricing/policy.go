@@ -0,0 +1,225 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+// Sources recorded against price changes
+const (
+	SourceSale          = "sale"
+	SourceScheduled     = "scheduled"
+	SourceManual        = "manual"
+	SourcePriceSchedule = "price_schedule"
+	SourcePriceRevert   = "price_schedule_revert"
+)
+
+// EffectivePolicy is the merged set of limits that apply to a product.
+// Each limit comes from the most specific active policy that sets it.
+type EffectivePolicy struct {
+	ProductId         int                  `json:"product_id"`
+	FloorPrice        *float64             `json:"floor_price"`
+	CeilingPrice      *float64             `json:"ceiling_price"`
+	MaxChangePct      *float64             `json:"max_change_pct"`
+	MaxDailyChangePct *float64             `json:"max_daily_change_pct"`
+	MinMarginPct      *float64             `json:"min_margin_pct"`
+	CostPrice         *float64             `json:"cost_price"`
+	Policies          []repo.PricingPolicy `json:"policies"`
+}
+
+// PriceChange is a proposed change to one of a product's prices
+type PriceChange struct {
+	ProductId    int
+	PriceType    repo.PriceType
+	OldPrice     float64
+	NewPrice     float64
+	Source       string
+	ModelVersion string
+}
+
+// PolicyViolation is returned when a price change breaks a pricing policy
+type PolicyViolation struct {
+	Reason string
+}
+
+func (v *PolicyViolation) Error() string {
+	return "pricing policy violation: " + v.Reason
+}
+
+// Guard checks price changes against pricing policies and records the ones it rejects
+type Guard struct {
+	db *sqlx.DB
+}
+
+func NewGuard(db *sqlx.DB) *Guard {
+	return &Guard{db: db}
+}
+
+// EffectivePolicy resolves the policy limits that apply to a product
+func (g *Guard) EffectivePolicy(ctx context.Context, q sqlx.QueryerContext, productId int) (*EffectivePolicy, error) {
+	effective := &EffectivePolicy{ProductId: productId}
+
+	err := sqlx.GetContext(ctx, q, &effective.CostPrice, `
+		SELECT cost_price
+		FROM product_metrics
+		WHERE product_id = $1
+	`, productId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get cost price: %w", err)
+	}
+
+	policiesQuery := `
+		SELECT pp.*
+		FROM pricing_policies pp
+		JOIN products p ON p.id = $1
+		WHERE pp.is_active = TRUE
+		AND (
+			(pp.scope = 'product' AND pp.scope_id = p.id)
+			OR (pp.scope = 'category' AND pp.scope_id = p.category_id)
+			OR (pp.scope = 'brand' AND pp.scope_id = p.brand_id)
+		)
+		ORDER BY CASE pp.scope WHEN 'product' THEN 0 WHEN 'category' THEN 1 ELSE 2 END
+	`
+	if err = sqlx.SelectContext(ctx, q, &effective.Policies, policiesQuery, productId); err != nil {
+		return nil, fmt.Errorf("failed to get pricing policies: %w", err)
+	}
+
+	for _, policy := range effective.Policies {
+		effective.FloorPrice = firstSet(effective.FloorPrice, policy.FloorPrice)
+		effective.CeilingPrice = firstSet(effective.CeilingPrice, policy.CeilingPrice)
+		effective.MaxChangePct = firstSet(effective.MaxChangePct, policy.MaxChangePct)
+		effective.MaxDailyChangePct = firstSet(effective.MaxDailyChangePct, policy.MaxDailyChangePct)
+		effective.MinMarginPct = firstSet(effective.MinMarginPct, policy.MinMarginPct)
+	}
+	return effective, nil
+}
+
+// Check validates a price change against the product's policies.
+// It returns a *PolicyViolation when the change is not allowed.
+func (g *Guard) Check(ctx context.Context, q sqlx.QueryerContext, change PriceChange) error {
+	policy, err := g.EffectivePolicy(ctx, q, change.ProductId)
+	if err != nil {
+		return err
+	}
+
+	// The daily limit is measured from the first adjusted price of the day
+	dayOpenPrice := change.OldPrice
+	if policy.MaxDailyChangePct != nil && change.PriceType == repo.PriceTypeAdjusted {
+		err = sqlx.GetContext(ctx, q, &dayOpenPrice, `
+			SELECT old_price
+			FROM price_adjustments
+			WHERE product_id = $1 AND price_type = 'adjusted' AND created_at >= CURRENT_DATE
+			ORDER BY created_at ASC
+			LIMIT 1
+		`, change.ProductId)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get opening price: %w", err)
+		}
+	}
+
+	return policy.Evaluate(change, dayOpenPrice)
+}
+
+// Evaluate applies the policy limits to a price change
+func (p *EffectivePolicy) Evaluate(change PriceChange, dayOpenPrice float64) error {
+	newPrice := change.NewPrice
+
+	if p.FloorPrice != nil && newPrice < *p.FloorPrice {
+		return &PolicyViolation{Reason: fmt.Sprintf("price %.2f is below the floor of %.2f", newPrice, *p.FloorPrice)}
+	}
+	if p.CeilingPrice != nil && newPrice > *p.CeilingPrice {
+		return &PolicyViolation{Reason: fmt.Sprintf("price %.2f is above the ceiling of %.2f", newPrice, *p.CeilingPrice)}
+	}
+	if p.MinMarginPct != nil && p.CostPrice != nil {
+		minPrice := roundPrice(*p.CostPrice * (1 + *p.MinMarginPct/100))
+		if newPrice < minPrice {
+			return &PolicyViolation{Reason: fmt.Sprintf("price %.2f is below the %.2f%% minimum margin over cost (%.2f)", newPrice, *p.MinMarginPct, minPrice)}
+		}
+	}
+	if p.MaxChangePct != nil {
+		if pct := percentChange(change.OldPrice, newPrice); pct > *p.MaxChangePct {
+			return &PolicyViolation{Reason: fmt.Sprintf("change of %.2f%% exceeds the %.2f%% limit per adjustment", pct, *p.MaxChangePct)}
+		}
+	}
+	if p.MaxDailyChangePct != nil && change.PriceType == repo.PriceTypeAdjusted {
+		if pct := percentChange(dayOpenPrice, newPrice); pct > *p.MaxDailyChangePct {
+			return &PolicyViolation{Reason: fmt.Sprintf("change of %.2f%% since the start of the day exceeds the %.2f%% daily limit", pct, *p.MaxDailyChangePct)}
+		}
+	}
+	return nil
+}
+
+// Clamp moves a proposed price from oldPrice into the policy limits: the floor and ceiling, the
+// minimum margin over cost, and the per-adjustment and daily limits measured from oldPrice.
+// Limits that contradict each other can leave no price that passes; Evaluate still catches it.
+func (p *EffectivePolicy) Clamp(oldPrice, newPrice float64) float64 {
+	lower, upper := math.Inf(-1), math.Inf(1)
+	if p.FloorPrice != nil {
+		lower = math.Max(lower, *p.FloorPrice)
+	}
+	if p.CeilingPrice != nil {
+		upper = math.Min(upper, *p.CeilingPrice)
+	}
+	if p.MinMarginPct != nil && p.CostPrice != nil {
+		lower = math.Max(lower, roundPrice(*p.CostPrice*(1+*p.MinMarginPct/100)))
+	}
+	for _, limit := range []*float64{p.MaxChangePct, p.MaxDailyChangePct} {
+		if limit == nil || oldPrice <= 0 {
+			continue
+		}
+		lower = math.Max(lower, changeBound(oldPrice, *limit, -0.01))
+		upper = math.Min(upper, changeBound(oldPrice, *limit, 0.01))
+	}
+	return math.Min(math.Max(newPrice, lower), upper)
+}
+
+// changeBound is the furthest whole-cent price from oldPrice, in the direction of step, that
+// changes it by no more than limitPct as percentChange measures it
+func changeBound(oldPrice, limitPct, step float64) float64 {
+	bound := roundPrice(oldPrice * (1 + math.Copysign(limitPct, step)/100))
+	for percentChange(oldPrice, bound) > limitPct {
+		bound = roundPrice(bound - step)
+	}
+	return bound
+}
+
+// Reject records a price change that was blocked by a policy
+func (g *Guard) Reject(ctx context.Context, e sqlx.ExecerContext, change PriceChange, reason string) error {
+	var modelVersion *string
+	if change.ModelVersion != "" {
+		modelVersion = &change.ModelVersion
+	}
+	query := `
+		INSERT INTO price_adjustment_rejections (product_id, price_type, old_price, proposed_price, source, model_version, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := e.ExecContext(ctx, query, change.ProductId, change.PriceType, change.OldPrice, change.NewPrice, change.Source, modelVersion, reason)
+	if err != nil {
+		return fmt.Errorf("failed to record price rejection: %w", err)
+	}
+	logging.LogInfo("Pricing: rejected %s price %.2f for product %d (%s): %s",
+		change.PriceType, change.NewPrice, change.ProductId, change.Source, reason)
+	return nil
+}
+
+// percentChange returns the absolute change from old to new as a percentage of old
+func percentChange(oldPrice, newPrice float64) float64 {
+	if oldPrice <= 0 {
+		return 0
+	}
+	return math.Abs(newPrice-oldPrice) / oldPrice * 100
+}
+
+func firstSet(current, candidate *float64) *float64 {
+	if current != nil {
+		return current
+	}
+	return candidate
+}
@@ -0,0 +1,146 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestEffectivePolicyEvaluate(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       EffectivePolicy
+		priceType    repo.PriceType
+		oldPrice     float64
+		newPrice     float64
+		dayOpenPrice float64
+		violates     bool
+	}{
+		{name: "no limits", oldPrice: 100, newPrice: 300, dayOpenPrice: 100},
+		{name: "below the floor", policy: EffectivePolicy{FloorPrice: ptr(90)}, oldPrice: 100, newPrice: 89.99, violates: true},
+		{name: "at the floor", policy: EffectivePolicy{FloorPrice: ptr(90)}, oldPrice: 100, newPrice: 90},
+		{name: "above the ceiling", policy: EffectivePolicy{CeilingPrice: ptr(110)}, oldPrice: 100, newPrice: 110.01, violates: true},
+		{name: "at the ceiling", policy: EffectivePolicy{CeilingPrice: ptr(110)}, oldPrice: 100, newPrice: 110},
+		{
+			name:     "below the minimum margin",
+			policy:   EffectivePolicy{MinMarginPct: ptr(25), CostPrice: ptr(80)},
+			oldPrice: 110, newPrice: 99.99, violates: true,
+		},
+		{
+			name:     "at the minimum margin",
+			policy:   EffectivePolicy{MinMarginPct: ptr(25), CostPrice: ptr(80)},
+			oldPrice: 110, newPrice: 100,
+		},
+		{
+			name:     "margin without a cost price",
+			policy:   EffectivePolicy{MinMarginPct: ptr(25)},
+			oldPrice: 110, newPrice: 1,
+		},
+		{name: "change above the limit", policy: EffectivePolicy{MaxChangePct: ptr(5)}, oldPrice: 100, newPrice: 94, violates: true},
+		{name: "change within the limit", policy: EffectivePolicy{MaxChangePct: ptr(5)}, oldPrice: 100, newPrice: 105},
+		{
+			name:      "daily change above the limit",
+			policy:    EffectivePolicy{MaxDailyChangePct: ptr(10)},
+			priceType: repo.PriceTypeAdjusted,
+			oldPrice:  108, newPrice: 112, dayOpenPrice: 100, violates: true,
+		},
+		{
+			name:      "daily change within the limit",
+			policy:    EffectivePolicy{MaxDailyChangePct: ptr(10)},
+			priceType: repo.PriceTypeAdjusted,
+			oldPrice:  108, newPrice: 110, dayOpenPrice: 100,
+		},
+		{
+			name:      "daily limit only applies to adjusted prices",
+			policy:    EffectivePolicy{MaxDailyChangePct: ptr(10)},
+			priceType: repo.PriceTypeBase,
+			oldPrice:  100, newPrice: 150, dayOpenPrice: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceType := tt.priceType
+			if priceType == "" {
+				priceType = repo.PriceTypeAdjusted
+			}
+			change := PriceChange{ProductId: 1, PriceType: priceType, OldPrice: tt.oldPrice, NewPrice: tt.newPrice}
+
+			err := tt.policy.Evaluate(change, tt.dayOpenPrice)
+			var violation *PolicyViolation
+			if tt.violates && !errors.As(err, &violation) {
+				t.Errorf("Evaluate() = %v, want a policy violation", err)
+			}
+			if !tt.violates && err != nil {
+				t.Errorf("Evaluate() = %v, want no violation", err)
+			}
+		})
+	}
+}
+
+func TestEffectivePolicyClamp(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   EffectivePolicy
+		oldPrice float64
+		newPrice float64
+		want     float64
+	}{
+		{name: "no limits", oldPrice: 100, newPrice: 300, want: 300},
+		{name: "up to the floor", policy: EffectivePolicy{FloorPrice: ptr(90)}, oldPrice: 100, newPrice: 50, want: 90},
+		{name: "down to the ceiling", policy: EffectivePolicy{CeilingPrice: ptr(110)}, oldPrice: 100, newPrice: 150, want: 110},
+		{
+			name:     "up to the minimum margin",
+			policy:   EffectivePolicy{MinMarginPct: ptr(25), CostPrice: ptr(80)},
+			oldPrice: 110, newPrice: 88, want: 100,
+		},
+		{name: "within the change limit", policy: EffectivePolicy{MaxChangePct: ptr(10)}, oldPrice: 100, newPrice: 95, want: 95},
+		{name: "down to the change limit", policy: EffectivePolicy{MaxChangePct: ptr(10)}, oldPrice: 100, newPrice: 130, want: 110},
+		{name: "up to the daily limit", policy: EffectivePolicy{MaxDailyChangePct: ptr(5)}, oldPrice: 100, newPrice: 80, want: 95},
+		{name: "rounded inside the change limit", policy: EffectivePolicy{MaxChangePct: ptr(7)}, oldPrice: 19.99, newPrice: 25, want: 21.38},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Clamp(tt.oldPrice, tt.newPrice)
+			if !approxEqual(got, tt.want) {
+				t.Errorf("Clamp(%.2f, %.2f) = %v, want %v", tt.oldPrice, tt.newPrice, got, tt.want)
+			}
+			change := PriceChange{PriceType: repo.PriceTypeAdjusted, OldPrice: tt.oldPrice, NewPrice: got}
+			if err := tt.policy.Evaluate(change, tt.oldPrice); err != nil {
+				t.Errorf("Evaluate() of the clamped price = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestEffectivePolicyClampPassesEvaluate(t *testing.T) {
+	policy := EffectivePolicy{MaxChangePct: ptr(7.5), MaxDailyChangePct: ptr(12)}
+	for cents := 1; cents <= 100000; cents += 7 {
+		oldPrice := float64(cents) / 100
+		for _, ratio := range []float64{0.5, 1.5} {
+			change := PriceChange{
+				PriceType: repo.PriceTypeAdjusted,
+				OldPrice:  oldPrice,
+				NewPrice:  policy.Clamp(oldPrice, oldPrice*ratio),
+			}
+			if err := policy.Evaluate(change, oldPrice); err != nil {
+				t.Fatalf("Evaluate() of %.2f clamped from %.2f = %v, want nil", change.NewPrice, oldPrice, err)
+			}
+		}
+	}
+}
+
+func TestEffectivePolicyClampConflictingLimits(t *testing.T) {
+	// A ceiling below the minimum margin leaves no price that passes
+	policy := EffectivePolicy{CeilingPrice: ptr(90), MinMarginPct: ptr(25), CostPrice: ptr(80)}
+	change := PriceChange{OldPrice: 95, NewPrice: policy.Clamp(95, 120)}
+	var violation *PolicyViolation
+	if err := policy.Evaluate(change, 95); !errors.As(err, &violation) {
+		t.Errorf("Evaluate() = %v, want a PolicyViolation", err)
+	}
+}
@@ -0,0 +1,213 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrProposalNotFound   = errors.New("price proposal not found")
+	ErrProposalNotPending = errors.New("price proposal is not pending")
+	ErrProposalHeld       = errors.New("a temporary scheduled price is in effect for the product")
+)
+
+// ReviewConfig controls whether model adjustments wait for admin approval.
+// In review mode, changes of at most AutoApprovePct percent are still applied straight away.
+type ReviewConfig struct {
+	Enabled        bool
+	AutoApprovePct float64
+}
+
+// needsReview reports whether an adjustment has to wait in the proposal queue
+func (r ReviewConfig) needsReview(adj Adjustment) bool {
+	return r.Enabled && percentChange(adj.OldPrice, adj.NewPrice) > r.AutoApprovePct
+}
+
+// ProposalQueue stores adjustments awaiting review and applies or rejects them
+type ProposalQueue struct {
+	db    *sqlx.DB
+	guard *Guard
+}
+
+func NewProposalQueue(db *sqlx.DB, guard *Guard) *ProposalQueue {
+	return &ProposalQueue{db: db, guard: guard}
+}
+
+// record inserts a proposal for an adjustment, superseding any pending proposal for the same product
+func (q *ProposalQueue) record(ctx context.Context, tx *sqlx.Tx, adj Adjustment, status repo.ProposalStatus) (int, error) {
+	if status == repo.ProposalStatusPending {
+		supersedeQuery := `
+			UPDATE price_proposals
+			SET status = 'superseded'
+			WHERE product_id = $1 AND status = 'pending'
+		`
+		if _, err := tx.ExecContext(ctx, supersedeQuery, adj.ProductId); err != nil {
+			return 0, fmt.Errorf("failed to supersede pending proposal: %w", err)
+		}
+	}
+
+	changePct := 0.0
+	if adj.OldPrice > 0 {
+		changePct = (adj.NewPrice - adj.OldPrice) / adj.OldPrice * 100
+	}
+
+	var id int
+	insertQuery := `
+		INSERT INTO price_proposals (product_id, old_price, new_price, change_pct, model_version, confidence_score, source, status, reviewed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CASE WHEN $9 THEN NOW() END)
+		RETURNING id
+	`
+	reviewed := status != repo.ProposalStatusPending
+	err := tx.QueryRowContext(ctx, insertQuery, adj.ProductId, adj.OldPrice, adj.NewPrice, roundPrice(changePct),
+		adj.ModelVersion, adj.ConfidenceScore, adj.Source, status, reviewed).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record price proposal: %w", err)
+	}
+	return id, nil
+}
+
+// lockPending loads a proposal for review, locking it until the transaction ends
+func (q *ProposalQueue) lockPending(ctx context.Context, tx *sqlx.Tx, id int) (*repo.PriceProposal, error) {
+	var proposal repo.PriceProposal
+	err := tx.GetContext(ctx, &proposal, `SELECT * FROM price_proposals WHERE id = $1 FOR UPDATE`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProposalNotFound
+		}
+		return nil, fmt.Errorf("failed to get price proposal: %w", err)
+	}
+	if proposal.Status != repo.ProposalStatusPending {
+		return nil, ErrProposalNotPending
+	}
+	return &proposal, nil
+}
+
+// close marks a proposal as reviewed and returns its updated row
+func (q *ProposalQueue) close(ctx context.Context, tx *sqlx.Tx, id int, status repo.ProposalStatus, reviewer, note string) (*repo.PriceProposal, error) {
+	var reviewNote *string
+	if note != "" {
+		reviewNote = &note
+	}
+
+	var proposal repo.PriceProposal
+	query := `
+		UPDATE price_proposals
+		SET status = $1, reviewed_by = $2, review_note = $3, reviewed_at = NOW()
+		WHERE id = $4
+		RETURNING *
+	`
+	if err := tx.GetContext(ctx, &proposal, query, status, reviewer, reviewNote, id); err != nil {
+		return nil, fmt.Errorf("failed to update price proposal: %w", err)
+	}
+	return &proposal, nil
+}
+
+// Approve applies a pending proposal to the live price.
+// The policies are checked again against the current price; a proposal that no longer
+// passes is rejected and the *PolicyViolation is returned alongside it. While a temporary
+// scheduled price is in effect the proposal stays pending and ErrProposalHeld is returned.
+func (q *ProposalQueue) Approve(ctx context.Context, id int, reviewer string) (*repo.PriceProposal, error) {
+	tx, err := q.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	proposal, err := q.lockPending(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentPrice float64
+	priceQuery := `
+		SELECT COALESCE(adjusted_price, base_price)
+		FROM product_metrics
+		WHERE product_id = $1
+		FOR UPDATE
+	`
+	if err = tx.GetContext(ctx, &currentPrice, priceQuery, proposal.ProductId); err != nil {
+		return nil, fmt.Errorf("failed to get current price: %w", err)
+	}
+
+	// The schedule reverts to the price it replaced when it ends, which would undo the approval
+	held, err := heldBySchedule(ctx, tx, proposal.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return nil, ErrProposalHeld
+	}
+
+	adj := Adjustment{
+		ProductId:    proposal.ProductId,
+		OldPrice:     currentPrice,
+		NewPrice:     proposal.NewPrice,
+		ModelVersion: proposal.ModelVersion,
+		Source:       proposal.Source,
+	}
+	if proposal.ConfidenceScore != nil {
+		adj.ConfidenceScore = *proposal.ConfidenceScore
+	}
+
+	change := adj.priceChange()
+	err = q.guard.Check(ctx, tx, change)
+	var violation *PolicyViolation
+	if errors.As(err, &violation) {
+		if err = q.guard.Reject(ctx, tx, change, violation.Reason); err != nil {
+			return nil, err
+		}
+		if proposal, err = q.close(ctx, tx, id, repo.ProposalStatusRejected, reviewer, violation.Reason); err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return proposal, violation
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = applyAdjustment(ctx, tx, adj); err != nil {
+		return nil, err
+	}
+	if proposal, err = q.close(ctx, tx, id, repo.ProposalStatusApproved, reviewer, ""); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: %s approved proposal %d, product %d from %.2f to %.2f",
+		reviewer, id, adj.ProductId, adj.OldPrice, adj.NewPrice)
+	return proposal, nil
+}
+
+// Reject discards a pending proposal without touching the live price
+func (q *ProposalQueue) Reject(ctx context.Context, id int, reviewer, note string) (*repo.PriceProposal, error) {
+	tx, err := q.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err = q.lockPending(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	proposal, err := q.close(ctx, tx, id, repo.ProposalStatusRejected, reviewer, note)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: %s rejected proposal %d for product %d", reviewer, id, proposal.ProductId)
+	return proposal, nil
+}
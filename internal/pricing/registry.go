@@ -0,0 +1,346 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrModelNotFound   = errors.New("model not found")
+	ErrNoActiveModel   = errors.New("no active model")
+	ErrNoPreviousModel = errors.New("no previously active model to roll back to")
+	ErrModelIsActive   = errors.New("model is active")
+)
+
+const coefficientColumns = `
+	id, model_version, training_date, sample_size,
+	training_window_start, training_window_end,
+	COALESCE(r_squared, 0) AS r_squared,
+	COALESCE(mse, 0) AS mse,
+	COALESCE(rmse, 0) AS rmse,
+	COALESCE(mae, 0) AS mae,
+	intercept,
+	COALESCE(days_since_last_sale_coef, 0) AS days_since_last_sale_coef,
+	COALESCE(sales_velocity_coef, 0) AS sales_velocity_coef,
+	COALESCE(total_sales_count_coef, 0) AS total_sales_count_coef,
+	COALESCE(total_sales_value_coef, 0) AS total_sales_value_coef,
+	COALESCE(category_percentile_coef, 0) AS category_percentile_coef,
+	COALESCE(review_score_coef, 0) AS review_score_coef,
+	COALESCE(wishlist_to_sales_ratio_coef, 0) AS wishlist_to_sales_ratio_coef,
+	COALESCE(days_since_restock_coef, 0) AS days_since_restock_coef,
+	status, promoted_at, retired_at,
+	created_at, updated_at
+`
+
+// Registry tracks the state of trained models.
+// Exactly one model is active and sets prices; at most one shadow model is evaluated alongside it.
+type Registry struct {
+	db *sqlx.DB
+}
+
+func NewRegistry(db *sqlx.DB) *Registry {
+	return &Registry{db: db}
+}
+
+// ShadowSummary aggregates the price differences logged for a shadow model
+type ShadowSummary struct {
+	ShadowModelVersion string  `json:"shadow_model_version" db:"shadow_model_version"`
+	Predictions        int     `json:"predictions" db:"predictions"`
+	Products           int     `json:"products" db:"products"`
+	MeanDiff           float64 `json:"mean_diff" db:"mean_diff"`
+	MeanAbsDiff        float64 `json:"mean_abs_diff" db:"mean_abs_diff"`
+	MaxAbsDiff         float64 `json:"max_abs_diff" db:"max_abs_diff"`
+}
+
+func (r *Registry) byStatus(ctx context.Context, q sqlx.QueryerContext, status repo.ModelStatus) (*repo.PriceModelCoefficients, error) {
+	var coef repo.PriceModelCoefficients
+	query := `SELECT ` + coefficientColumns + ` FROM price_model_coefficients WHERE status = $1`
+	if err := sqlx.GetContext(ctx, q, &coef, query, status); err != nil {
+		return nil, err
+	}
+	return &coef, nil
+}
+
+// Active returns the model that currently sets prices
+func (r *Registry) Active(ctx context.Context) (*repo.PriceModelCoefficients, error) {
+	coef, err := r.byStatus(ctx, r.db, repo.ModelStatusActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoActiveModel
+		}
+		return nil, fmt.Errorf("failed to get active model: %w", err)
+	}
+	return coef, nil
+}
+
+// Shadow returns the model in shadow mode, or nil when there is none
+func (r *Registry) Shadow(ctx context.Context) (*repo.PriceModelCoefficients, error) {
+	coef, err := r.byStatus(ctx, r.db, repo.ModelStatusShadow)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get shadow model: %w", err)
+	}
+	return coef, nil
+}
+
+// Get returns a model by its version
+func (r *Registry) Get(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	var coef repo.PriceModelCoefficients
+	query := `SELECT ` + coefficientColumns + ` FROM price_model_coefficients WHERE model_version = $1`
+	if err := r.db.GetContext(ctx, &coef, query, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrModelNotFound
+		}
+		return nil, fmt.Errorf("failed to get model: %w", err)
+	}
+	return &coef, nil
+}
+
+// List returns every model, newest first
+func (r *Registry) List(ctx context.Context) ([]repo.PriceModelCoefficients, error) {
+	var models []repo.PriceModelCoefficients
+	query := `SELECT ` + coefficientColumns + ` FROM price_model_coefficients ORDER BY training_date DESC`
+	if err := r.db.SelectContext(ctx, &models, query); err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	return models, nil
+}
+
+// lock loads a model by version and locks it until the transaction ends
+func (r *Registry) lock(ctx context.Context, tx *sqlx.Tx, version string) (*repo.PriceModelCoefficients, error) {
+	var coef repo.PriceModelCoefficients
+	query := `SELECT ` + coefficientColumns + ` FROM price_model_coefficients WHERE model_version = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &coef, query, version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrModelNotFound
+		}
+		return nil, fmt.Errorf("failed to get model: %w", err)
+	}
+	return &coef, nil
+}
+
+func (r *Registry) retireActive(ctx context.Context, tx *sqlx.Tx) error {
+	query := `
+		UPDATE price_model_coefficients
+		SET status = 'retired', retired_at = NOW()
+		WHERE status = 'active'
+	`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to retire active model: %w", err)
+	}
+	return nil
+}
+
+// Promote makes a model the active one and retires the model it replaces
+func (r *Registry) Promote(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	coef, err := r.lock(ctx, tx, version)
+	if err != nil {
+		return nil, err
+	}
+	if coef.Status == repo.ModelStatusActive {
+		return nil, ErrModelIsActive
+	}
+
+	if err = r.retireActive(ctx, tx); err != nil {
+		return nil, err
+	}
+	promoteQuery := `
+		UPDATE price_model_coefficients
+		SET status = 'active', promoted_at = NOW(), retired_at = NULL
+		WHERE id = $1
+	`
+	if _, err = tx.ExecContext(ctx, promoteQuery, coef.Id); err != nil {
+		return nil, fmt.Errorf("failed to promote model: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: promoted model %s", version)
+	return r.Get(ctx, version)
+}
+
+// Rollback retires the active model and reactivates the one promoted before it
+func (r *Registry) Rollback(ctx context.Context) (*repo.PriceModelCoefficients, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	active, err := r.byStatus(ctx, tx, repo.ModelStatusActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoActiveModel
+		}
+		return nil, fmt.Errorf("failed to get active model: %w", err)
+	}
+
+	// Promotion times are kept on rollback, so repeated rollbacks step further back
+	var previous repo.PriceModelCoefficients
+	previousQuery := `
+		SELECT ` + coefficientColumns + `
+		FROM price_model_coefficients
+		WHERE status = 'retired' AND promoted_at IS NOT NULL AND promoted_at < $1
+		ORDER BY promoted_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`
+	if err = tx.GetContext(ctx, &previous, previousQuery, active.PromotedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoPreviousModel
+		}
+		return nil, fmt.Errorf("failed to get previous model: %w", err)
+	}
+
+	if err = r.retireActive(ctx, tx); err != nil {
+		return nil, err
+	}
+	restoreQuery := `
+		UPDATE price_model_coefficients
+		SET status = 'active', retired_at = NULL
+		WHERE id = $1
+	`
+	if _, err = tx.ExecContext(ctx, restoreQuery, previous.Id); err != nil {
+		return nil, fmt.Errorf("failed to restore model: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: rolled back model %s to %s", active.ModelVersion, previous.ModelVersion)
+	return r.Get(ctx, previous.ModelVersion)
+}
+
+// StartShadow puts a model in shadow mode. A model already in shadow mode goes back to candidate.
+func (r *Registry) StartShadow(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	coef, err := r.lock(ctx, tx, version)
+	if err != nil {
+		return nil, err
+	}
+	if coef.Status == repo.ModelStatusActive {
+		return nil, ErrModelIsActive
+	}
+
+	demoteQuery := `
+		UPDATE price_model_coefficients
+		SET status = 'candidate'
+		WHERE status = 'shadow' AND id <> $1
+	`
+	if _, err = tx.ExecContext(ctx, demoteQuery, coef.Id); err != nil {
+		return nil, fmt.Errorf("failed to stop current shadow model: %w", err)
+	}
+	shadowQuery := `
+		UPDATE price_model_coefficients
+		SET status = 'shadow', retired_at = NULL
+		WHERE id = $1
+	`
+	if _, err = tx.ExecContext(ctx, shadowQuery, coef.Id); err != nil {
+		return nil, fmt.Errorf("failed to start shadow model: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: model %s is now in shadow mode", version)
+	return r.Get(ctx, version)
+}
+
+// Retire takes a candidate or shadow model out of use. The active model can only be replaced, not retired.
+func (r *Registry) Retire(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	coef, err := r.lock(ctx, tx, version)
+	if err != nil {
+		return nil, err
+	}
+	if coef.Status == repo.ModelStatusActive {
+		return nil, ErrModelIsActive
+	}
+
+	retireQuery := `
+		UPDATE price_model_coefficients
+		SET status = 'retired', retired_at = COALESCE(retired_at, NOW())
+		WHERE id = $1
+	`
+	if _, err = tx.ExecContext(ctx, retireQuery, coef.Id); err != nil {
+		return nil, fmt.Errorf("failed to retire model: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logging.LogInfo("Pricing: retired model %s", version)
+	return r.Get(ctx, version)
+}
+
+// ShadowReport summarises the logged differences for a shadow model and returns the most recent ones
+func (r *Registry) ShadowReport(ctx context.Context, version string, limit int) (*ShadowSummary, []repo.ShadowPrediction, error) {
+	summary := ShadowSummary{ShadowModelVersion: version}
+	summaryQuery := `
+		SELECT
+			COUNT(*) AS predictions,
+			COUNT(DISTINCT product_id) AS products,
+			COALESCE(AVG(price_diff), 0) AS mean_diff,
+			COALESCE(AVG(ABS(price_diff)), 0) AS mean_abs_diff,
+			COALESCE(MAX(ABS(price_diff)), 0) AS max_abs_diff
+		FROM model_shadow_predictions
+		WHERE shadow_model_version = $1
+	`
+	if err := r.db.GetContext(ctx, &summary, summaryQuery, version); err != nil {
+		return nil, nil, fmt.Errorf("failed to summarise shadow predictions: %w", err)
+	}
+
+	var predictions []repo.ShadowPrediction
+	listQuery := `
+		SELECT *
+		FROM model_shadow_predictions
+		WHERE shadow_model_version = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	if err := r.db.SelectContext(ctx, &predictions, listQuery, version, limit); err != nil {
+		return nil, nil, fmt.Errorf("failed to get shadow predictions: %w", err)
+	}
+	return &summary, predictions, nil
+}
+
+// logShadow records the price a shadow model would have set next to the active model's price
+func logShadow(ctx context.Context, e sqlx.ExecerContext, active, shadow Adjustment) error {
+	diff := roundPrice(shadow.NewPrice - active.NewPrice)
+	query := `
+		INSERT INTO model_shadow_predictions (product_id, active_model_version, shadow_model_version, active_price, shadow_price, price_diff, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := e.ExecContext(ctx, query, active.ProductId, active.ModelVersion, shadow.ModelVersion, active.NewPrice, shadow.NewPrice, diff, active.Source)
+	if err != nil {
+		return fmt.Errorf("failed to log shadow prediction: %w", err)
+	}
+	logging.LogInfo("Pricing: shadow model %s would price product %d at %.2f, active model %s at %.2f (diff %+.2f)",
+		shadow.ModelVersion, active.ProductId, shadow.NewPrice, active.ModelVersion, active.NewPrice, diff)
+	return nil
+}
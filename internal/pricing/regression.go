@@ -0,0 +1,197 @@
+package pricing
+
+import (
+	"errors"
+	"math"
+)
+
+// Model is a fitted linear model: ratio = Intercept + Σ(Weights[i] * x[i])
+type Model struct {
+	Intercept float64
+	Weights   []float64
+}
+
+// FitMetrics summarises how well a model fits the data it was trained on
+type FitMetrics struct {
+	SampleSize int
+	RSquared   float64
+	MSE        float64
+	RMSE       float64
+	MAE        float64
+}
+
+// collinearityTolerance is the share of a feature's variance that must remain
+// after the earlier features are accounted for for it to get a weight
+const collinearityTolerance = 1e-10
+
+// Predict returns the model output for a single feature vector
+func (m Model) Predict(x []float64) float64 {
+	y := m.Intercept
+	for i, w := range m.Weights {
+		if i < len(x) {
+			y += w * x[i]
+		}
+	}
+	return y
+}
+
+// FitOLS fits an ordinary least squares model with an intercept.
+// Features that are constant or a linear combination of earlier features
+// get a zero weight instead of failing the fit, which keeps training usable
+// on the small, sparse datasets a young shop produces.
+func FitOLS(X [][]float64, y []float64) (Model, FitMetrics, error) {
+	n := len(X)
+	if n == 0 {
+		return Model{}, FitMetrics{}, errors.New("no training data available")
+	}
+	if n != len(y) {
+		return Model{}, FitMetrics{}, errors.New("feature and target lengths differ")
+	}
+	p := len(X[0])
+	for _, row := range X {
+		if len(row) != p {
+			return Model{}, FitMetrics{}, errors.New("feature rows have different lengths")
+		}
+	}
+
+	// Centre the data so the intercept drops out of the normal equations
+	xMean := make([]float64, p)
+	var yMean float64
+	for i, row := range X {
+		for j, v := range row {
+			xMean[j] += v
+		}
+		yMean += y[i]
+	}
+	for j := range xMean {
+		xMean[j] /= float64(n)
+	}
+	yMean /= float64(n)
+
+	xtx := make([][]float64, p)
+	for j := range xtx {
+		xtx[j] = make([]float64, p)
+	}
+	xty := make([]float64, p)
+	for i, row := range X {
+		yc := y[i] - yMean
+		for j := 0; j < p; j++ {
+			xj := row[j] - xMean[j]
+			xty[j] += xj * yc
+			for k := j; k < p; k++ {
+				xtx[j][k] += xj * (row[k] - xMean[k])
+			}
+		}
+	}
+	for j := 0; j < p; j++ {
+		for k := 0; k < j; k++ {
+			xtx[j][k] = xtx[k][j]
+		}
+	}
+
+	weights := solveNormalEquations(xtx, xty)
+
+	model := Model{Weights: weights, Intercept: yMean}
+	for j, w := range weights {
+		model.Intercept -= w * xMean[j]
+	}
+
+	return model, Evaluate(model, X, y), nil
+}
+
+// Evaluate computes fit metrics for a model against a dataset
+func Evaluate(m Model, X [][]float64, y []float64) FitMetrics {
+	n := len(y)
+	metrics := FitMetrics{SampleSize: n}
+	if n == 0 {
+		return metrics
+	}
+
+	var yMean float64
+	for _, v := range y {
+		yMean += v
+	}
+	yMean /= float64(n)
+
+	var ssRes, ssTot, absErr float64
+	for i, row := range X {
+		residual := y[i] - m.Predict(row)
+		ssRes += residual * residual
+		absErr += math.Abs(residual)
+		ssTot += (y[i] - yMean) * (y[i] - yMean)
+	}
+
+	metrics.MSE = ssRes / float64(n)
+	metrics.RMSE = math.Sqrt(metrics.MSE)
+	metrics.MAE = absErr / float64(n)
+	if ssTot > 0 {
+		metrics.RSquared = 1 - ssRes/ssTot
+	} else if ssRes == 0 {
+		// A constant target predicted perfectly
+		metrics.RSquared = 1
+	}
+	return metrics
+}
+
+// solveNormalEquations solves A·w = b for a symmetric positive semi-definite A
+// with a Cholesky factorisation that skips columns it cannot pivot on
+func solveNormalEquations(a [][]float64, b []float64) []float64 {
+	p := len(b)
+	weights := make([]float64, p)
+
+	l := make([][]float64, p)
+	for j := range l {
+		l[j] = make([]float64, p)
+	}
+	var active []int
+
+	for j := 0; j < p; j++ {
+		row := make([]float64, p)
+		for _, k := range active {
+			sum := a[j][k]
+			for _, m := range active {
+				if m >= k {
+					break
+				}
+				sum -= row[m] * l[k][m]
+			}
+			row[k] = sum / l[k][k]
+		}
+		diag := a[j][j]
+		for _, k := range active {
+			diag -= row[k] * row[k]
+		}
+		// Drop the column when almost none of its variance is left unexplained
+		if a[j][j] <= 0 || diag <= collinearityTolerance*a[j][j] {
+			continue
+		}
+		row[j] = math.Sqrt(diag)
+		l[j] = row
+		active = append(active, j)
+	}
+
+	// Forward substitution: L·z = b
+	z := make([]float64, p)
+	for _, j := range active {
+		sum := b[j]
+		for _, k := range active {
+			if k >= j {
+				break
+			}
+			sum -= l[j][k] * z[k]
+		}
+		z[j] = sum / l[j][j]
+	}
+
+	// Back substitution: Lᵀ·w = z
+	for i := len(active) - 1; i >= 0; i-- {
+		j := active[i]
+		sum := z[j]
+		for _, k := range active[i+1:] {
+			sum -= l[k][j] * weights[k]
+		}
+		weights[j] = sum / l[j][j]
+	}
+
+	return weights
+}
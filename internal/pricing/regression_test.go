@@ -0,0 +1,143 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+const tolerance = 1e-9
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < tolerance
+}
+
+func TestFitOLS(t *testing.T) {
+	tests := []struct {
+		name      string
+		X         [][]float64
+		y         []float64
+		intercept float64
+		weights   []float64
+	}{
+		{
+			name:      "known fit",
+			X:         [][]float64{{0, 1}, {1, 0}, {2, 3}, {3, 1}, {4, 2}},
+			y:         []float64{1 + 2*0 - 1*1, 1 + 2*1 - 1*0, 1 + 2*2 - 1*3, 1 + 2*3 - 1*1, 1 + 2*4 - 1*2},
+			intercept: 1,
+			weights:   []float64{2, -1},
+		},
+		{
+			name:      "collinear column gets no weight",
+			X:         [][]float64{{1, 2}, {2, 4}, {3, 6}, {4, 8}},
+			y:         []float64{3, 5, 7, 9},
+			intercept: 1,
+			weights:   []float64{2, 0},
+		},
+		{
+			name:      "constant column gets no weight",
+			X:         [][]float64{{1, 5}, {2, 5}, {3, 5}, {4, 5}},
+			y:         []float64{0.5, 1, 1.5, 2},
+			intercept: 0,
+			weights:   []float64{0.5, 0},
+		},
+		{
+			name:      "single row fits its target",
+			X:         [][]float64{{3, 7}},
+			y:         []float64{1.1},
+			intercept: 1.1,
+			weights:   []float64{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, metrics, err := FitOLS(tt.X, tt.y)
+			if err != nil {
+				t.Fatalf("FitOLS() error = %v", err)
+			}
+			if !approxEqual(model.Intercept, tt.intercept) {
+				t.Errorf("Intercept = %v, want %v", model.Intercept, tt.intercept)
+			}
+			for i, want := range tt.weights {
+				if !approxEqual(model.Weights[i], want) {
+					t.Errorf("Weights[%d] = %v, want %v", i, model.Weights[i], want)
+				}
+			}
+			if metrics.SampleSize != len(tt.y) {
+				t.Errorf("SampleSize = %d, want %d", metrics.SampleSize, len(tt.y))
+			}
+			if !approxEqual(metrics.RSquared, 1) || !approxEqual(metrics.MSE, 0) {
+				t.Errorf("metrics = %+v, want a perfect fit", metrics)
+			}
+		})
+	}
+}
+
+func TestFitOLSErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		X    [][]float64
+		y    []float64
+	}{
+		{name: "no rows", X: nil, y: nil},
+		{name: "target length differs", X: [][]float64{{1}, {2}}, y: []float64{1}},
+		{name: "ragged rows", X: [][]float64{{1, 2}, {3}}, y: []float64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := FitOLS(tt.X, tt.y); err == nil {
+				t.Error("FitOLS() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	model := Model{Intercept: 0, Weights: []float64{1}}
+	X := [][]float64{{1}, {2}, {3}, {4}}
+
+	tests := []struct {
+		name string
+		y    []float64
+		want FitMetrics
+	}{
+		{
+			name: "residuals of one",
+			// Residuals are 1, -1, 1, -1 around a mean of 2.5
+			y:    []float64{2, 1, 4, 3},
+			want: FitMetrics{SampleSize: 4, RSquared: 0.2, MSE: 1, RMSE: 1, MAE: 1},
+		},
+		{
+			name: "perfect fit",
+			y:    []float64{1, 2, 3, 4},
+			want: FitMetrics{SampleSize: 4, RSquared: 1},
+		},
+		{
+			name: "no rows",
+			want: FitMetrics{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Evaluate(model, X[:len(tt.y)], tt.y)
+			if got.SampleSize != tt.want.SampleSize || !approxEqual(got.RSquared, tt.want.RSquared) ||
+				!approxEqual(got.MSE, tt.want.MSE) || !approxEqual(got.RMSE, tt.want.RMSE) || !approxEqual(got.MAE, tt.want.MAE) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateConstantTarget(t *testing.T) {
+	X := [][]float64{{1}, {2}}
+	y := []float64{3, 3}
+
+	if got := Evaluate(Model{Intercept: 3, Weights: []float64{0}}, X, y); !approxEqual(got.RSquared, 1) {
+		t.Errorf("RSquared = %v for a constant target predicted exactly, want 1", got.RSquared)
+	}
+	if got := Evaluate(Model{Intercept: 2, Weights: []float64{0}}, X, y); !approxEqual(got.RSquared, 0) {
+		t.Errorf("RSquared = %v for a constant target predicted wrongly, want 0", got.RSquared)
+	}
+}
@@ -0,0 +1,169 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRepriceWindow is how long sales of a product are gathered before it is repriced
+	DefaultRepriceWindow = 2 * time.Second
+	// DefaultRepriceConcurrency is how many products are repriced at once
+	DefaultRepriceConcurrency = 4
+)
+
+// ErrRepricerStopped is returned for reprices requested after the repricer stopped, and for
+// reprices still gathering when it did
+var ErrRepricerStopped = errors.New("repricer stopped")
+
+// RepricerConfig configures how sale repricing is coalesced
+type RepricerConfig struct {
+	Window      time.Duration
+	Concurrency int
+}
+
+// RepricerStats counts the reprice requests received and the reprices they turned into
+type RepricerStats struct {
+	Received  int64 `json:"received"`
+	Coalesced int64 `json:"coalesced"`
+	Executed  int64 `json:"executed"`
+	Failed    int64 `json:"failed"`
+	Pending   int   `json:"pending"`
+}
+
+// pendingReprice is a reprice gathering requests for one product
+type pendingReprice struct {
+	done  chan struct{}
+	err   error
+	timer *time.Timer
+}
+
+// Repricer reprices products after sales. Requests for the same product within Window are
+// coalesced into a single compute and adjust, and at most Concurrency products are repriced at
+// once. Every request waits for the reprice it joined and gets its result. Reprices run until
+// the context given to Run is cancelled.
+type Repricer struct {
+	backend   PricingBackend
+	config    RepricerConfig
+	slots     chan struct{}
+	ctx       context.Context
+	cancel    context.CancelFunc
+	running   sync.WaitGroup
+	mutex     sync.Mutex
+	pending   map[int]*pendingReprice
+	stopped   bool
+	received  atomic.Int64
+	coalesced atomic.Int64
+	executed  atomic.Int64
+	failed    atomic.Int64
+}
+
+func NewRepricer(backend PricingBackend, config RepricerConfig) *Repricer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Repricer{
+		backend: backend,
+		config:  config,
+		slots:   make(chan struct{}, max(config.Concurrency, 1)),
+		ctx:     ctx,
+		cancel:  cancel,
+		pending: map[int]*pendingReprice{},
+	}
+}
+
+// Run waits for ctx to be cancelled and then stops the repricer: reprices still gathering
+// fail with ErrRepricerStopped, those in flight are cancelled, and Run returns once they end
+func (r *Repricer) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	r.mutex.Lock()
+	r.stopped = true
+	for productId, p := range r.pending {
+		// A reprice whose timer already fired is left to run and see the cancellation
+		if p.timer.Stop() {
+			delete(r.pending, productId)
+			p.err = ErrRepricerStopped
+			close(p.done)
+			r.running.Done()
+		}
+	}
+	r.mutex.Unlock()
+
+	r.cancel()
+	r.running.Wait()
+}
+
+// Reprice recomputes a product's features and reprices it, joining a reprice already
+// gathering for the product when there is one
+func (r *Repricer) Reprice(ctx context.Context, productId int) error {
+	r.received.Add(1)
+
+	r.mutex.Lock()
+	if r.stopped {
+		r.mutex.Unlock()
+		return ErrRepricerStopped
+	}
+	p, ok := r.pending[productId]
+	if ok {
+		r.coalesced.Add(1)
+	} else {
+		p = &pendingReprice{done: make(chan struct{})}
+		r.pending[productId] = p
+		r.running.Add(1)
+		p.timer = time.AfterFunc(r.config.Window, func() { r.run(productId, p) })
+	}
+	r.mutex.Unlock()
+
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run executes a gathered reprice. The product stops gathering first, so a sale that lands
+// while it runs gets a reprice of its own.
+func (r *Repricer) run(productId int, p *pendingReprice) {
+	defer r.running.Done()
+	r.mutex.Lock()
+	delete(r.pending, productId)
+	r.mutex.Unlock()
+
+	select {
+	case r.slots <- struct{}{}:
+		defer func() { <-r.slots }()
+	case <-r.ctx.Done():
+		p.err = ErrRepricerStopped
+		close(p.done)
+		return
+	}
+
+	ctx := r.ctx
+	err := r.backend.ComputeFeatures(ctx, productId)
+	if err == nil {
+		err = r.backend.AdjustPrice(ctx, productId)
+	}
+	r.executed.Add(1)
+	if err != nil {
+		r.failed.Add(1)
+	}
+	p.err = err
+	close(p.done)
+}
+
+// Stats returns the repricer's counters since it started
+func (r *Repricer) Stats() RepricerStats {
+	r.mutex.Lock()
+	pending := len(r.pending)
+	r.mutex.Unlock()
+	return RepricerStats{
+		Received:  r.received.Load(),
+		Coalesced: r.coalesced.Load(),
+		Executed:  r.executed.Load(),
+		Failed:    r.failed.Load(),
+		Pending:   pending,
+	}
+}
@@ -0,0 +1,44 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRepricerStop(t *testing.T) {
+	fake := NewFakeBackend()
+	repricer := NewRepricer(fake, RepricerConfig{Window: time.Hour, Concurrency: 1})
+	ctx, stop := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		repricer.Run(ctx)
+		close(stopped)
+	}()
+
+	gathering := make(chan error, 1)
+	go func() {
+		gathering <- repricer.Reprice(context.Background(), 1)
+	}()
+	for repricer.Stats().Pending == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	stop()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after its context was cancelled")
+	}
+
+	if err := <-gathering; !errors.Is(err, ErrRepricerStopped) {
+		t.Errorf("gathering Reprice() = %v, want ErrRepricerStopped", err)
+	}
+	if err := repricer.Reprice(context.Background(), 2); !errors.Is(err, ErrRepricerStopped) {
+		t.Errorf("Reprice() after stopping = %v, want ErrRepricerStopped", err)
+	}
+	if len(fake.ComputedProducts) != 0 {
+		t.Errorf("ComputedProducts = %v, want no reprices after stopping", fake.ComputedProducts)
+	}
+}
@@ -0,0 +1,334 @@
+package pricing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrScheduleNotFound = errors.New("price schedule not found")
+	ErrScheduleClosed   = errors.New("price schedule has already finished")
+)
+
+// scheduleBatchSize caps how many schedules a single run applies or reverts
+const scheduleBatchSize = 100
+
+// PriceSchedules applies scheduled price changes when they start and reverts temporary ones
+// when they end. Every change is logged in price_adjustments, which announces it over SSE.
+type PriceSchedules struct {
+	db    *sqlx.DB
+	guard *Guard
+}
+
+func NewPriceSchedules(db *sqlx.DB, guard *Guard) *PriceSchedules {
+	return &PriceSchedules{db: db, guard: guard}
+}
+
+// lockedSchedule is a schedule row locked for update, with whether its end has already passed
+type lockedSchedule struct {
+	repo.PriceSchedule
+	Expired bool `db:"expired"`
+}
+
+func (s *PriceSchedules) lock(ctx context.Context, tx *sqlx.Tx, id int) (*lockedSchedule, error) {
+	var schedule lockedSchedule
+	query := `
+		SELECT *, (ends_at IS NOT NULL AND ends_at <= NOW()) AS expired
+		FROM price_schedules
+		WHERE id = $1
+		FOR UPDATE
+	`
+	if err := tx.GetContext(ctx, &schedule, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("failed to get price schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// currentPrice locks a product's prices and returns the one of the given type
+func currentPrice(ctx context.Context, tx *sqlx.Tx, productId int, priceType repo.PriceType) (float64, error) {
+	column := "COALESCE(adjusted_price, base_price)"
+	if priceType == repo.PriceTypeBase {
+		column = "base_price"
+	}
+	var price float64
+	query := `SELECT ` + column + ` FROM product_metrics WHERE product_id = $1 FOR UPDATE`
+	if err := tx.GetContext(ctx, &price, query, productId); err != nil {
+		return 0, fmt.Errorf("failed to get current price: %w", err)
+	}
+	return price, nil
+}
+
+// setPrice writes a scheduled price and logs it in price_adjustments
+func setPrice(ctx context.Context, tx *sqlx.Tx, schedule *repo.PriceSchedule, oldPrice, newPrice float64, source string) error {
+	updateQuery := `
+		UPDATE product_metrics
+		SET adjusted_price = $1, last_price_update = NOW()
+		WHERE product_id = $2
+	`
+	if schedule.PriceType == repo.PriceTypeBase {
+		updateQuery = `
+			UPDATE product_metrics
+			SET base_price = $1
+			WHERE product_id = $2
+		`
+	}
+	if _, err := tx.ExecContext(ctx, updateQuery, newPrice, schedule.ProductId); err != nil {
+		return fmt.Errorf("failed to update %s price: %w", schedule.PriceType, err)
+	}
+
+	insertLogQuery := `
+		INSERT INTO price_adjustments (product_id, old_price, new_price, source, price_type, schedule_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := tx.ExecContext(ctx, insertLogQuery, schedule.ProductId, oldPrice, newPrice, source, schedule.PriceType, schedule.Id)
+	if err != nil {
+		return fmt.Errorf("failed to log price adjustment: %w", err)
+	}
+	return nil
+}
+
+// finish moves a schedule to its next status and returns the updated row
+func finish(ctx context.Context, tx *sqlx.Tx, query string, args ...interface{}) (*repo.PriceSchedule, error) {
+	var schedule repo.PriceSchedule
+	if err := tx.GetContext(ctx, &schedule, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to update price schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// apply puts a due schedule's price in place. A change the pricing policies reject marks the
+// schedule failed, and a temporary change whose window has already passed is never applied.
+func (s *PriceSchedules) apply(ctx context.Context, id int) (*repo.PriceSchedule, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	schedule, err := s.lock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != repo.ScheduleStatusScheduled {
+		return &schedule.PriceSchedule, nil
+	}
+
+	failQuery := `
+		UPDATE price_schedules
+		SET status = 'failed', failure_reason = $1
+		WHERE id = $2
+		RETURNING *
+	`
+	var updated *repo.PriceSchedule
+	if schedule.Expired {
+		updated, err = finish(ctx, tx, failQuery, "the schedule ended before it could be applied", id)
+	} else {
+		updated, err = s.applyLocked(ctx, tx, &schedule.PriceSchedule, failQuery)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+func (s *PriceSchedules) applyLocked(ctx context.Context, tx *sqlx.Tx, schedule *repo.PriceSchedule, failQuery string) (*repo.PriceSchedule, error) {
+	oldPrice, err := currentPrice(ctx, tx, schedule.ProductId, schedule.PriceType)
+	if err != nil {
+		return nil, err
+	}
+
+	change := PriceChange{
+		ProductId: schedule.ProductId,
+		PriceType: schedule.PriceType,
+		OldPrice:  oldPrice,
+		NewPrice:  schedule.Price,
+		Source:    SourcePriceSchedule,
+	}
+	err = s.guard.Check(ctx, tx, change)
+	var violation *PolicyViolation
+	if errors.As(err, &violation) {
+		if err = s.guard.Reject(ctx, tx, change, violation.Reason); err != nil {
+			return nil, err
+		}
+		return finish(ctx, tx, failQuery, violation.Reason, schedule.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = setPrice(ctx, tx, schedule, oldPrice, schedule.Price, SourcePriceSchedule); err != nil {
+		return nil, err
+	}
+	return finish(ctx, tx, `
+		UPDATE price_schedules
+		SET status = CASE WHEN ends_at IS NULL THEN 'completed' ELSE 'active' END,
+			revert_price = $1, applied_at = NOW()
+		WHERE id = $2
+		RETURNING *
+	`, oldPrice, schedule.Id)
+}
+
+// revertLocked restores the price a temporary schedule replaced. The restored price was in
+// place before the schedule started, so it is not checked against the policies again.
+func revertLocked(ctx context.Context, tx *sqlx.Tx, schedule *repo.PriceSchedule, status repo.ScheduleStatus) (*repo.PriceSchedule, error) {
+	oldPrice, err := currentPrice(ctx, tx, schedule.ProductId, schedule.PriceType)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.RevertPrice != nil {
+		if err = setPrice(ctx, tx, schedule, oldPrice, *schedule.RevertPrice, SourcePriceRevert); err != nil {
+			return nil, err
+		}
+	}
+	return finish(ctx, tx, `
+		UPDATE price_schedules
+		SET status = $1, reverted_at = NOW()
+		WHERE id = $2
+		RETURNING *
+	`, status, schedule.Id)
+}
+
+// revert ends a temporary schedule whose end time has passed
+func (s *PriceSchedules) revert(ctx context.Context, id int) (*repo.PriceSchedule, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	schedule, err := s.lock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != repo.ScheduleStatusActive {
+		return &schedule.PriceSchedule, nil
+	}
+	updated, err := revertLocked(ctx, tx, &schedule.PriceSchedule, repo.ScheduleStatusCompleted)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// RunDue applies every schedule whose start has come and reverts every temporary schedule
+// whose end has passed. A schedule that fails is logged and left for the rest to proceed.
+func (s *PriceSchedules) RunDue(ctx context.Context) (applied int, reverted int, err error) {
+	var dueIds []int
+	dueQuery := `
+		SELECT id
+		FROM price_schedules
+		WHERE status = 'scheduled' AND starts_at <= NOW()
+		ORDER BY starts_at
+		LIMIT $1
+	`
+	if err = s.db.SelectContext(ctx, &dueIds, dueQuery, scheduleBatchSize); err != nil {
+		return 0, 0, fmt.Errorf("failed to get due price schedules: %w", err)
+	}
+	for _, id := range dueIds {
+		schedule, err := s.apply(ctx, id)
+		if err != nil {
+			logging.LogError("Pricing: failed to apply price schedule %d: %v", id, err)
+			continue
+		}
+		switch schedule.Status {
+		case repo.ScheduleStatusActive, repo.ScheduleStatusCompleted:
+			applied++
+			logging.LogInfo("Pricing: applied price schedule %d, product %d %s price to %.2f",
+				id, schedule.ProductId, schedule.PriceType, schedule.Price)
+		case repo.ScheduleStatusFailed:
+			logging.LogInfo("Pricing: price schedule %d failed: %s", id, *schedule.FailureReason)
+		}
+	}
+
+	var endedIds []int
+	endedQuery := `
+		SELECT id
+		FROM price_schedules
+		WHERE status = 'active' AND ends_at <= NOW()
+		ORDER BY ends_at
+		LIMIT $1
+	`
+	if err = s.db.SelectContext(ctx, &endedIds, endedQuery, scheduleBatchSize); err != nil {
+		return applied, 0, fmt.Errorf("failed to get ended price schedules: %w", err)
+	}
+	for _, id := range endedIds {
+		schedule, err := s.revert(ctx, id)
+		if err != nil {
+			logging.LogError("Pricing: failed to revert price schedule %d: %v", id, err)
+			continue
+		}
+		if schedule.Status == repo.ScheduleStatusCompleted {
+			reverted++
+			logging.LogInfo("Pricing: reverted price schedule %d, product %d", id, schedule.ProductId)
+		}
+	}
+	return applied, reverted, nil
+}
+
+// Cancel stops a schedule. One that has not started is dropped; an active temporary
+// change is reverted straight away.
+func (s *PriceSchedules) Cancel(ctx context.Context, id int) (*repo.PriceSchedule, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	schedule, err := s.lock(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *repo.PriceSchedule
+	switch schedule.Status {
+	case repo.ScheduleStatusScheduled:
+		updated, err = finish(ctx, tx, `
+			UPDATE price_schedules
+			SET status = 'cancelled'
+			WHERE id = $1
+			RETURNING *
+		`, id)
+	case repo.ScheduleStatusActive:
+		updated, err = revertLocked(ctx, tx, &schedule.PriceSchedule, repo.ScheduleStatusCancelled)
+	default:
+		return nil, ErrScheduleClosed
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return updated, nil
+}
+
+// heldBySchedule reports whether a temporary adjusted price is in effect for a product,
+// in which case the model leaves its price alone until the schedule ends
+func heldBySchedule(ctx context.Context, q sqlx.QueryerContext, productId int) (bool, error) {
+	var held bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM price_schedules
+			WHERE product_id = $1 AND price_type = 'adjusted' AND status = 'active'
+		)
+	`
+	if err := sqlx.GetContext(ctx, q, &held, query, productId); err != nil {
+		return false, fmt.Errorf("failed to check price schedules: %w", err)
+	}
+	return held, nil
+}
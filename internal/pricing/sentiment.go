@@ -0,0 +1,95 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jonreiter/govader"
+	"github.com/lib/pq"
+)
+
+// Weights of the review_score blend, the same as the regression sidecar's
+const (
+	reviewRatingWeight    = 0.7
+	reviewSentimentWeight = 0.3
+)
+
+// sentimentAnalyzer is the VADER analyzer, loaded on first use and safe to share
+var sentimentAnalyzer = sync.OnceValue(govader.NewSentimentIntensityAnalyzer)
+
+// reviewSentiment is the VADER compound score of a review's text, from -1 to 1
+func reviewSentiment(text string) float64 {
+	return sentimentAnalyzer().PolarityScores(text).Compound
+}
+
+// blendReviewScore combines a product's average rating with the sentiment of its review texts:
+// 70% the rating and 30% the mean compound score moved onto the same 0 to 5 scale.
+// A product without review texts scores its average rating.
+func blendReviewScore(averageRating float64, sentiments []float64) float64 {
+	if len(sentiments) == 0 {
+		return averageRating
+	}
+	total := 0.0
+	for _, sentiment := range sentiments {
+		total += sentiment
+	}
+	sentimentScore := (total/float64(len(sentiments)) + 1) * 2.5
+	return reviewRatingWeight*averageRating + reviewSentimentWeight*sentimentScore
+}
+
+// reviewText is a product's review text with when it was written
+type reviewText struct {
+	ProductId  int       `db:"product_id"`
+	ReviewText string    `db:"review_text"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// reviewTexts retrieves the non-empty review texts of the products in productIds (every product
+// when empty), oldest first for each product
+func reviewTexts(ctx context.Context, q sqlx.QueryerContext, productIds []int) ([]reviewText, error) {
+	var reviews []reviewText
+	query := `
+		SELECT product_id, review_text, created_at
+		FROM reviews
+		WHERE review_text IS NOT NULL AND review_text != ''
+		AND (cardinality($1::int[]) = 0 OR product_id = ANY($1::int[]))
+		ORDER BY product_id, created_at, id
+	`
+	if err := sqlx.SelectContext(ctx, q, &reviews, query, pq.Array(productIds)); err != nil {
+		return nil, fmt.Errorf("failed to get review texts: %w", err)
+	}
+	return reviews, nil
+}
+
+// reviewSentiments scores review texts, grouped by product in the order they were read
+type reviewSentiments struct {
+	times      []time.Time
+	sentiments []float64
+}
+
+func scoreReviews(reviews []reviewText) map[int]*reviewSentiments {
+	scored := map[int]*reviewSentiments{}
+	for _, review := range reviews {
+		product, ok := scored[review.ProductId]
+		if !ok {
+			product = &reviewSentiments{}
+			scored[review.ProductId] = product
+		}
+		product.times = append(product.times, review.CreatedAt)
+		product.sentiments = append(product.sentiments, reviewSentiment(review.ReviewText))
+	}
+	return scored
+}
+
+// before returns the sentiments of the reviews written before t
+func (r *reviewSentiments) before(t time.Time) []float64 {
+	if r == nil {
+		return nil
+	}
+	n := sort.Search(len(r.times), func(i int) bool { return !r.times[i].Before(t) })
+	return r.sentiments[:n]
+}
@@ -0,0 +1,55 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlendReviewScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		rating     float64
+		sentiments []float64
+		want       float64
+	}{
+		{name: "no review texts", rating: 4.2, want: 4.2},
+		{name: "all positive", rating: 4, sentiments: []float64{1}, want: 0.7*4 + 0.3*5},
+		{name: "all negative", rating: 1, sentiments: []float64{-1, -1}, want: 0.7},
+		{name: "neutral on average", rating: 3, sentiments: []float64{0.5, -0.5}, want: 0.7*3 + 0.3*2.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blendReviewScore(tt.rating, tt.sentiments); !approxEqual(got, tt.want) {
+				t.Errorf("blendReviewScore(%v, %v) = %v, want %v", tt.rating, tt.sentiments, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewSentiment(t *testing.T) {
+	if got := reviewSentiment("Great product, I love it!"); got <= 0.5 {
+		t.Errorf("reviewSentiment() of a glowing review = %v, want above 0.5", got)
+	}
+	if got := reviewSentiment("Terrible quality, it broke after a day."); got >= 0 {
+		t.Errorf("reviewSentiment() of a bad review = %v, want below 0", got)
+	}
+}
+
+func TestReviewSentimentsBefore(t *testing.T) {
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	scored := scoreReviews([]reviewText{
+		{ProductId: 1, ReviewText: "Love it", CreatedAt: day.Add(-time.Hour)},
+		{ProductId: 1, ReviewText: "Hate it", CreatedAt: day.Add(time.Hour)},
+		{ProductId: 2, ReviewText: "Fine", CreatedAt: day.Add(-time.Hour)},
+	})
+
+	if got := scored[1].before(day); len(got) != 1 || got[0] <= 0 {
+		t.Errorf("before(day) for product 1 = %v, want the one positive review", got)
+	}
+	if got := scored[1].before(day.AddDate(0, 0, 1)); len(got) != 2 {
+		t.Errorf("before(next day) for product 1 = %v, want both reviews", got)
+	}
+	if got := scored[3].before(day); got != nil {
+		t.Errorf("before(day) for a product without reviews = %v, want nil", got)
+	}
+}
@@ -0,0 +1,28 @@
+package repo
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending	OutboxStatus = "pending"
+	OutboxStatusDone	OutboxStatus = "done"
+	OutboxStatusFailed	OutboxStatus = "failed"
+	OutboxStatusDiscarded	OutboxStatus = "discarded"
+)
+
+type OutboxEvent struct {
+	Id			int				`db:"id" json:"id"`
+	Topic		string			`db:"topic" json:"topic"`
+	Payload		json.RawMessage	`db:"payload" json:"payload"`
+	Status		OutboxStatus	`db:"status" json:"status"`
+	Attempts	int				`db:"attempts" json:"attempts"`
+	AvailableAt	time.Time		`db:"available_at" json:"available_at"`
+	LastError	*string			`db:"last_error" json:"last_error"`
+	ProcessedAt	*time.Time		`db:"processed_at" json:"processed_at"`
+	CreatedAt	time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt	time.Time		`db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,49 @@
+package repo
+
+import "time"
+
+type JobRunStatus string
+
+const (
+	JobRunStatusQueued		JobRunStatus = "queued"
+	JobRunStatusRunning		JobRunStatus = "running"
+	JobRunStatusSucceeded	JobRunStatus = "succeeded"
+	JobRunStatusFailed		JobRunStatus = "failed"
+)
+
+type JobTrigger string
+
+const (
+	JobTriggerSchedule	JobTrigger = "schedule"
+	JobTriggerManual	JobTrigger = "manual"
+)
+
+type ScheduledJob struct {
+	Id				int				`db:"id" json:"id"`
+	Name			string			`db:"name" json:"name"`
+	Description		*string			`db:"description" json:"description"`
+	CronExpression	string			`db:"cron_expression" json:"cron_expression"`
+	IsPaused		bool			`db:"is_paused" json:"is_paused"`
+	NextRunAt		*time.Time		`db:"next_run_at" json:"next_run_at"`
+	LastRunAt		*time.Time		`db:"last_run_at" json:"last_run_at"`
+	LastStatus		*JobRunStatus	`db:"last_status" json:"last_status"`
+	UpdatedBy		*string			`db:"updated_by" json:"updated_by"`
+	CreatedAt		time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time		`db:"updated_at" json:"updated_at"`
+}
+
+type JobRun struct {
+	Id			int				`db:"id" json:"id"`
+	JobId		int				`db:"job_id" json:"job_id"`
+	Trigger		JobTrigger		`db:"trigger" json:"trigger"`
+	Status		JobRunStatus	`db:"status" json:"status"`
+	RequestedBy	*string			`db:"requested_by" json:"requested_by"`
+	Instance	*string			`db:"instance" json:"instance"`
+	Result		*string			`db:"result" json:"result"`
+	Error		*string			`db:"error" json:"error"`
+	StartedAt	*time.Time		`db:"started_at" json:"started_at"`
+	FinishedAt	*time.Time		`db:"finished_at" json:"finished_at"`
+	DurationMs	*int64			`db:"duration_ms" json:"duration_ms"`
+	CreatedAt	time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt	time.Time		`db:"updated_at" json:"updated_at"`
+}
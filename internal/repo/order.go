@@ -8,11 +8,36 @@ type OrderStatus string
 
 const (
 	OrderStatusPending		OrderStatus = "pending"
+	OrderStatusPaid			OrderStatus = "paid"
+	OrderStatusProcessing	OrderStatus = "processing"
+	OrderStatusShipped		OrderStatus = "shipped"
+	OrderStatusDelivered	OrderStatus = "delivered"
 	OrderStatusCancelled	OrderStatus = "cancelled"
+	OrderStatusRefunded		OrderStatus = "refunded"
 	OrderStatusFailed 		OrderStatus = "failed"
-	OrderStatusCompleted 	OrderStatus = "completed"
 )
 
+type ActorType string
+
+const (
+	ActorTypeCustomer	ActorType = "customer"
+	ActorTypeAdmin		ActorType = "admin"
+	ActorTypeSystem		ActorType = "system"
+)
+
+// OrderStatusChange is one entry in an order's status history
+type OrderStatusChange struct {
+	Id			int				`db:"id" json:"id"`
+	OrderId		int				`db:"order_id" json:"order_id"`
+	FromStatus	*OrderStatus	`db:"from_status" json:"from_status"`
+	ToStatus	OrderStatus		`db:"to_status" json:"to_status"`
+	ActorType	ActorType		`db:"actor_type" json:"actor_type"`
+	Actor		*string			`db:"actor" json:"actor"`
+	Reason		*string			`db:"reason" json:"reason"`
+	CreatedAt	time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt	time.Time		`db:"updated_at" json:"updated_at"`
+}
+
 type Order struct {
 	Id				int			`db:"id" json:"id"`
 	CustomerId		int			`db:"customer_id" json:"customer_id"`
@@ -29,6 +54,8 @@ type OrderItem struct {
 	ProductId		int			`db:"product_id" json:"product_id"`
 	Price			float64		`db:"price" json:"price"`
 	Quantity		int			`db:"quantity" json:"quantity"`
+	ExperimentId	*int		`db:"experiment_id" json:"-"`
+	ExperimentArm	*string		`db:"experiment_arm" json:"-"`
 	CreatedAt		time.Time	`db:"created_at" json:"created_at"`
 	UpdatedAt		time.Time	`db:"updated_at" json:"updated_at"`
 }
@@ -44,6 +71,7 @@ type OrderItemDetail struct {
 
 	ProductName				string		`db:"product_name" json:"product_name"`
 	ProductImagePath		*string		`db:"product_image_path" json:"product_image_path"`
+	SalePrice				*float64	`db:"sale_price" json:"sale_price,omitempty"`
 }
 
 type OrderWithItems struct {
@@ -56,4 +84,37 @@ type OrderWithItems struct {
 	UpdatedAt		time.Time	`db:"updated_at" json:"updated_at"`
 
 	Items 			[]OrderItemDetail `json:"items"`
+	Payments		[]Payment		`json:"payments,omitempty"`
+	History			[]OrderStatusChange `json:"history,omitempty"`
+}
+
+// OrderSummary is an order in a list, without its items
+type OrderSummary struct {
+	Id					int			`db:"id" json:"id"`
+	CustomerId			int			`db:"customer_id" json:"customer_id"`
+	CustomerUsername	string		`db:"customer_username" json:"customer_username,omitempty"`
+	TotalPrice			float64		`db:"total_price" json:"total_price"`
+	Status				OrderStatus	`db:"status" json:"status"`
+	PriceValidUntil 	time.Time	`db:"price_valid_until" json:"price_valid_until"`
+	ItemCount			int			`db:"item_count" json:"item_count"`
+	CreatedAt			time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt			time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+// OrderFilter narrows a list of orders; zero fields do not filter. From is inclusive and To
+// exclusive. Page counts from 1.
+type OrderFilter struct {
+	CustomerId	int
+	Status		OrderStatus
+	From		*time.Time
+	To			*time.Time
+	Page		int
+	PageSize	int
+}
+
+type OrderPage struct {
+	Orders		[]OrderSummary	`json:"orders"`
+	Total		int				`json:"total"`
+	Page		int				`json:"page"`
+	PageSize	int				`json:"page_size"`
 }
@@ -3,21 +3,19 @@ package repo
 import "time"
 
 type PricingFeatures struct {
-	Id                 		int       	`db:"id" json:"id"`
-	ProductId          		int       	`db:"product_id" json:"product_id"`
-	DaysSinceLastSale  		int       	`db:"days_since_last_sale" json:"days_since_last_sale"`
-	SalesVelocity      		float64   	`db:"sales_velocity" json:"sales_velocity"`
-	TotalSalesCount    		int       	`db:"total_sales_count" json:"total_sales_count"`
-	TotalSalesValue    		float64   	`db:"total_sales_value" json:"total_sales_value"`
-	CategoryRank       		int       	`db:"category_rank" json:"category_rank"`
-	CategoryPercentile 		float64   	`db:"category_percentile" json:"category_percentile"`
-	ReviewScore        		float64   	`db:"review_score" json:"review_score"`
-	WishlistToSalesRatio 	float64 	`db:"wishlist_to_sales_ratio" json:"wishlist_to_sales_ratio"`
-	DaysInStock        		int       	`db:"days_in_stock" json:"days_in_stock"`
-	SeasonalFactor     		float64   	`db:"seasonal_factor" json:"seasonal_factor"`
-	LastModelRun       		time.Time 	`db:"last_model_run" json:"last_model_run"`
-	CreatedAt          		time.Time 	`db:"created_at" json:"created_at"`
-	UpdatedAt          		time.Time 	`db:"updated_at" json:"updated_at"`
+	Id						int			`db:"id" json:"id"`
+	ProductId				int			`db:"product_id" json:"product_id"`
+	DaysSinceLastSale		int			`db:"days_since_last_sale" json:"days_since_last_sale"`
+	SalesVelocity			float64		`db:"sales_velocity" json:"sales_velocity"`
+	TotalSalesCount			int			`db:"total_sales_count" json:"total_sales_count"`
+	TotalSalesValue			float64		`db:"total_sales_value" json:"total_sales_value"`
+	CategoryPercentile		float64		`db:"category_percentile" json:"category_percentile"`
+	ReviewScore				float64		`db:"review_score" json:"review_score"`
+	WishlistToSalesRatio	float64		`db:"wishlist_to_sales_ratio" json:"wishlist_to_sales_ratio"`
+	DaysSinceRestock		int			`db:"days_since_restock" json:"days_since_restock"`
+	LastModelRun			*time.Time	`db:"last_model_run" json:"last_model_run"`
+	CreatedAt				time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt				time.Time	`db:"updated_at" json:"updated_at"`
 }
 
 type PriceAdjustment struct {
@@ -25,29 +23,258 @@ type PriceAdjustment struct {
 	ProductId		int			`db:"product_id" json:"product_id"`
 	OldPrice		float64		`db:"old_price" json:"old_price"`
 	NewPrice		float64		`db:"new_price" json:"new_price"`
-	ModelVersion	string		`db:"model_version" json:"model_version"`
-	ConfidenceScore	float32		`db:"confidence_score" json:"confidence_score"`
+	ModelVersion	*string		`db:"model_version" json:"model_version"`
+	ConfidenceScore	*float32	`db:"confidence_score" json:"confidence_score"`
+	Source			*string		`db:"source" json:"source"`
+	PriceType		PriceType	`db:"price_type" json:"price_type"`
+	ScheduleId		*int		`db:"schedule_id" json:"schedule_id"`
 	CreatedAt		time.Time	`db:"created_at" json:"created_at"`
 	UpdatedAt		time.Time	`db:"updated_at" json:"updated_at"`
 }
 
+// PriceHistoryPoint is a product's price over one day or week: the closing price and the
+// lowest and highest prices in effect
+type PriceHistoryPoint struct {
+	Date	time.Time	`json:"date"`
+	Price	float64		`json:"price"`
+	Low		float64		`json:"low"`
+	High	float64		`json:"high"`
+}
+
+type PriceHistory struct {
+	ProductId		int					`json:"product_id"`
+	Interval		string				`json:"interval"`
+	CurrentPrice	float64				`json:"current_price"`
+	Low30d			float64				`json:"low_30d"`
+	High30d			float64				`json:"high_30d"`
+	DroppedRecently	bool				`json:"dropped_recently"`
+	Points			[]PriceHistoryPoint	`json:"points"`
+}
+
+type ModelStatus string
+
+const (
+	ModelStatusCandidate	ModelStatus = "candidate"
+	ModelStatusActive		ModelStatus = "active"
+	ModelStatusShadow		ModelStatus = "shadow"
+	ModelStatusRetired		ModelStatus = "retired"
+)
+
 type PriceModelCoefficients struct {
-	Id                    	int       	`db:"id" json:"id"`
-	ModelVersion          	string    	`db:"model_version" json:"model_version"`
-	TrainingDate          	time.Time 	`db:"training_date" json:"training_date"`
-	SampleSize            	int       	`db:"sample_size" json:"sample_size"`
-	RSquared              	float64   	`db:"r_squared" json:"r_squared"`
-	Intercept             	float64   	`db:"intercept" json:"intercept"`
-	SalesCountCoef        	float64   	`db:"sales_count_coef" json:"sales_count_coef"`
-	SalesValueCoef        	float64   	`db:"sales_value_coef" json:"sales_value_coef"`
-	SalesVelocityCoef     	float64   	`db:"sales_velocity_coef" json:"sales_velocity_coef"`
-	DaysSinceSaleCoef     	float64   	`db:"days_since_sale_coef" json:"days_since_sale_coef"`
-	CategoryRankCoef      	float64   	`db:"category_rank_coef" json:"category_rank_coef"`
-	CategoryPercentileCoef 	float64  	`db:"category_percentile_coef" json:"category_percentile_coef"`
-	ReviewScoreCoef       	float64   	`db:"review_score_coef" json:"review_score_coef"`
-	WishlistRatioCoef     	float64   	`db:"wishlist_ratio_coef" json:"wishlist_ratio_coef"`
-	DaysInStockCoef       	float64   	`db:"days_in_stock_coef" json:"days_in_stock_coef"`
-	SeasonalFactorCoef    	float64   	`db:"seasonal_factor_coef" json:"seasonal_factor_coef"`
-	CreatedAt            	time.Time 	`db:"created_at" json:"created_at"`
-	UpdatedAt             	time.Time 	`db:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+	Id						int			`db:"id" json:"id"`
+	ModelVersion			string		`db:"model_version" json:"model_version"`
+	TrainingDate			time.Time	`db:"training_date" json:"training_date"`
+	SampleSize				int			`db:"sample_size" json:"sample_size"`
+	TrainingWindowStart		*time.Time	`db:"training_window_start" json:"training_window_start"`
+	TrainingWindowEnd		*time.Time	`db:"training_window_end" json:"training_window_end"`
+	RSquared				float64		`db:"r_squared" json:"r_squared"`
+	MSE						float64		`db:"mse" json:"mse"`
+	RMSE					float64		`db:"rmse" json:"rmse"`
+	MAE						float64		`db:"mae" json:"mae"`
+	Intercept				float64		`db:"intercept" json:"intercept"`
+	DaysSinceLastSaleCoef	float64		`db:"days_since_last_sale_coef" json:"days_since_last_sale_coef"`
+	SalesVelocityCoef		float64		`db:"sales_velocity_coef" json:"sales_velocity_coef"`
+	TotalSalesCountCoef		float64		`db:"total_sales_count_coef" json:"total_sales_count_coef"`
+	TotalSalesValueCoef		float64		`db:"total_sales_value_coef" json:"total_sales_value_coef"`
+	CategoryPercentileCoef	float64		`db:"category_percentile_coef" json:"category_percentile_coef"`
+	ReviewScoreCoef			float64		`db:"review_score_coef" json:"review_score_coef"`
+	WishlistToSalesRatioCoef	float64		`db:"wishlist_to_sales_ratio_coef" json:"wishlist_to_sales_ratio_coef"`
+	DaysSinceRestockCoef	float64		`db:"days_since_restock_coef" json:"days_since_restock_coef"`
+	Status					ModelStatus	`db:"status" json:"status"`
+	PromotedAt				*time.Time	`db:"promoted_at" json:"promoted_at"`
+	RetiredAt				*time.Time	`db:"retired_at" json:"retired_at"`
+	CreatedAt				time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt				time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+type PolicyScope string
+
+const (
+	PolicyScopeProduct		PolicyScope = "product"
+	PolicyScopeCategory		PolicyScope = "category"
+	PolicyScopeBrand		PolicyScope = "brand"
+)
+
+// PricingPolicy holds price guardrails; nil limits are not enforced
+type PricingPolicy struct {
+	Id					int			`db:"id" json:"id"`
+	Scope				PolicyScope	`db:"scope" json:"scope"`
+	ScopeId				int			`db:"scope_id" json:"scope_id"`
+	FloorPrice			*float64	`db:"floor_price" json:"floor_price"`
+	CeilingPrice		*float64	`db:"ceiling_price" json:"ceiling_price"`
+	MaxChangePct		*float64	`db:"max_change_pct" json:"max_change_pct"`
+	MaxDailyChangePct	*float64	`db:"max_daily_change_pct" json:"max_daily_change_pct"`
+	MinMarginPct		*float64	`db:"min_margin_pct" json:"min_margin_pct"`
+	IsActive			bool		`db:"is_active" json:"is_active"`
+	CreatedAt			time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt			time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+type PriceType string
+
+const (
+	PriceTypeAdjusted	PriceType = "adjusted"
+	PriceTypeBase		PriceType = "base"
+)
+
+type PriceAdjustmentRejection struct {
+	Id				int			`db:"id" json:"id"`
+	ProductId		int			`db:"product_id" json:"product_id"`
+	PriceType		PriceType	`db:"price_type" json:"price_type"`
+	OldPrice		float64		`db:"old_price" json:"old_price"`
+	ProposedPrice	float64		`db:"proposed_price" json:"proposed_price"`
+	Source			string		`db:"source" json:"source"`
+	ModelVersion	*string		`db:"model_version" json:"model_version"`
+	Reason			string		`db:"reason" json:"reason"`
+	CreatedAt		time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+type ProposalStatus string
+
+const (
+	ProposalStatusPending		ProposalStatus = "pending"
+	ProposalStatusApproved		ProposalStatus = "approved"
+	ProposalStatusAutoApproved	ProposalStatus = "auto_approved"
+	ProposalStatusRejected		ProposalStatus = "rejected"
+	ProposalStatusSuperseded	ProposalStatus = "superseded"
+)
+
+type PriceProposal struct {
+	Id				int				`db:"id" json:"id"`
+	ProductId		int				`db:"product_id" json:"product_id"`
+	OldPrice		float64			`db:"old_price" json:"old_price"`
+	NewPrice		float64			`db:"new_price" json:"new_price"`
+	ChangePct		float64			`db:"change_pct" json:"change_pct"`
+	ModelVersion	string			`db:"model_version" json:"model_version"`
+	ConfidenceScore	*float64		`db:"confidence_score" json:"confidence_score"`
+	Source			string			`db:"source" json:"source"`
+	Status			ProposalStatus	`db:"status" json:"status"`
+	ReviewedBy		*string			`db:"reviewed_by" json:"reviewed_by"`
+	ReviewNote		*string			`db:"review_note" json:"review_note"`
+	ReviewedAt		*time.Time		`db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt		time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time		`db:"updated_at" json:"updated_at"`
+}
+
+type ShadowPrediction struct {
+	Id					int			`db:"id" json:"id"`
+	ProductId			int			`db:"product_id" json:"product_id"`
+	ActiveModelVersion	string		`db:"active_model_version" json:"active_model_version"`
+	ShadowModelVersion	string		`db:"shadow_model_version" json:"shadow_model_version"`
+	ActivePrice			float64		`db:"active_price" json:"active_price"`
+	ShadowPrice			float64		`db:"shadow_price" json:"shadow_price"`
+	PriceDiff			float64		`db:"price_diff" json:"price_diff"`
+	Source				string		`db:"source" json:"source"`
+	CreatedAt			time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt			time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+// PricingFeatureSnapshot is a product's pricing features and prices at the time they were computed
+type PricingFeatureSnapshot struct {
+	Id						int			`db:"id" json:"id"`
+	ProductId				int			`db:"product_id" json:"product_id"`
+	DaysSinceLastSale		int			`db:"days_since_last_sale" json:"days_since_last_sale"`
+	SalesVelocity			float64		`db:"sales_velocity" json:"sales_velocity"`
+	TotalSalesCount			int			`db:"total_sales_count" json:"total_sales_count"`
+	TotalSalesValue			float64		`db:"total_sales_value" json:"total_sales_value"`
+	CategoryPercentile		float64		`db:"category_percentile" json:"category_percentile"`
+	ReviewScore				float64		`db:"review_score" json:"review_score"`
+	WishlistToSalesRatio	float64		`db:"wishlist_to_sales_ratio" json:"wishlist_to_sales_ratio"`
+	DaysSinceRestock		int			`db:"days_since_restock" json:"days_since_restock"`
+	BasePrice				*float64	`db:"base_price" json:"base_price"`
+	AdjustedPrice			*float64	`db:"adjusted_price" json:"adjusted_price"`
+	CapturedAt				time.Time	`db:"captured_at" json:"captured_at"`
+	CreatedAt				time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt				time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+type ElasticityScope string
+
+const (
+	ElasticityScopeProduct		ElasticityScope = "product"
+	ElasticityScopeCategory		ElasticityScope = "category"
+)
+
+// PriceElasticity is the estimated % change in units sold per 1% change in price
+type PriceElasticity struct {
+	Id				int				`db:"id" json:"id"`
+	Scope			ElasticityScope	`db:"scope" json:"scope"`
+	ScopeId			int				`db:"scope_id" json:"scope_id"`
+	Elasticity		float64			`db:"elasticity" json:"elasticity"`
+	RSquared		*float64		`db:"r_squared" json:"r_squared"`
+	Observations	int				`db:"observations" json:"observations"`
+	WindowDays		int				`db:"window_days" json:"window_days"`
+	EstimatedAt		time.Time		`db:"estimated_at" json:"estimated_at"`
+	CreatedAt		time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time		`db:"updated_at" json:"updated_at"`
+}
+
+type ExperimentStatus string
+
+const (
+	ExperimentStatusRunning		ExperimentStatus = "running"
+	ExperimentStatusStopped		ExperimentStatus = "stopped"
+)
+
+type ExperimentVariant string
+
+const (
+	ExperimentVariantMultiplier	ExperimentVariant = "multiplier"
+	ExperimentVariantModel		ExperimentVariant = "model"
+)
+
+type ExperimentArm string
+
+const (
+	ExperimentArmControl		ExperimentArm = "control"
+	ExperimentArmTreatment		ExperimentArm = "treatment"
+)
+
+// PriceExperiment splits customers between the live price and an alternative for a set of products
+type PriceExperiment struct {
+	Id					int					`db:"id" json:"id"`
+	Name				string				`db:"name" json:"name"`
+	Description			*string				`db:"description" json:"description"`
+	VariantType			ExperimentVariant	`db:"variant_type" json:"variant_type"`
+	PriceMultiplier		*float64			`db:"price_multiplier" json:"price_multiplier"`
+	ModelVersion		*string				`db:"model_version" json:"model_version"`
+	TreatmentPct		float64				`db:"treatment_pct" json:"treatment_pct"`
+	Status				ExperimentStatus	`db:"status" json:"status"`
+	CreatedBy			*string				`db:"created_by" json:"created_by"`
+	StartedAt			time.Time			`db:"started_at" json:"started_at"`
+	StoppedAt			*time.Time			`db:"stopped_at" json:"stopped_at"`
+	CreatedAt			time.Time			`db:"created_at" json:"created_at"`
+	UpdatedAt			time.Time			`db:"updated_at" json:"updated_at"`
+
+	ProductIds			[]int				`db:"-" json:"product_ids"`
+}
+
+type ScheduleStatus string
+
+const (
+	ScheduleStatusScheduled		ScheduleStatus = "scheduled"
+	ScheduleStatusActive		ScheduleStatus = "active"
+	ScheduleStatusCompleted		ScheduleStatus = "completed"
+	ScheduleStatusCancelled		ScheduleStatus = "cancelled"
+	ScheduleStatusFailed		ScheduleStatus = "failed"
+)
+
+// PriceSchedule is a price change set to take effect at StartsAt, reverted at EndsAt when set
+type PriceSchedule struct {
+	Id				int				`db:"id" json:"id"`
+	ProductId		int				`db:"product_id" json:"product_id"`
+	PriceType		PriceType		`db:"price_type" json:"price_type"`
+	Price			float64			`db:"price" json:"price"`
+	StartsAt		time.Time		`db:"starts_at" json:"starts_at"`
+	EndsAt			*time.Time		`db:"ends_at" json:"ends_at"`
+	RevertPrice		*float64		`db:"revert_price" json:"revert_price"`
+	Status			ScheduleStatus	`db:"status" json:"status"`
+	Note			*string			`db:"note" json:"note"`
+	FailureReason	*string			`db:"failure_reason" json:"failure_reason"`
+	CreatedBy		*string			`db:"created_by" json:"created_by"`
+	AppliedAt		*time.Time		`db:"applied_at" json:"applied_at"`
+	RevertedAt		*time.Time		`db:"reverted_at" json:"reverted_at"`
+	CreatedAt		time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time		`db:"updated_at" json:"updated_at"`
+}
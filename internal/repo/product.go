@@ -101,4 +101,7 @@ type ProductDetail struct {
     // Stock information
     StockQuantity    *int `db:"stock_quantity" json:"stock_quantity"`
     StockThreshold   *int `db:"stock_threshold" json:"stock_threshold"`
+
+    // Daily closing prices over the last few days, oldest first
+    PriceTrend []float64 `db:"-" json:"price_trend,omitempty"`
 }
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending	DeliveryStatus = "pending"
+	DeliveryStatusDelivered	DeliveryStatus = "delivered"
+	DeliveryStatusFailed	DeliveryStatus = "failed"
+)
+
+// Webhook is an endpoint subscribed to live events. The secret is only shown when the
+// webhook is created.
+type Webhook struct {
+	Id			int				`db:"id" json:"id"`
+	Url			string			`db:"url" json:"url"`
+	EventTypes	pq.StringArray	`db:"event_types" json:"event_types"`
+	Secret		string			`db:"secret" json:"secret,omitempty"`
+	Description	*string			`db:"description" json:"description"`
+	IsActive	bool			`db:"is_active" json:"is_active"`
+	CreatedBy	*string			`db:"created_by" json:"created_by"`
+	CreatedAt	time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt	time.Time		`db:"updated_at" json:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	Id				int				`db:"id" json:"id"`
+	WebhookId		int				`db:"webhook_id" json:"webhook_id"`
+	EventType		string			`db:"event_type" json:"event_type"`
+	Payload			json.RawMessage	`db:"payload" json:"payload"`
+	Status			DeliveryStatus	`db:"status" json:"status"`
+	Attempts		int				`db:"attempts" json:"attempts"`
+	ResponseStatus	*int			`db:"response_status" json:"response_status"`
+	ResponseBody	*string			`db:"response_body" json:"response_body"`
+	LastError		*string			`db:"last_error" json:"last_error"`
+	DeliveredAt		*time.Time		`db:"delivered_at" json:"delivered_at"`
+	CreatedAt		time.Time		`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time		`db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+// GetJobs retrieves every job with its schedule and latest outcome
+func (s *Scheduler) GetJobs(ctx context.Context) ([]repo.ScheduledJob, error) {
+	var jobs []repo.ScheduledJob
+	if err := s.db.SelectContext(ctx, &jobs, `SELECT * FROM scheduled_jobs ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetJob retrieves a job by its ID
+func (s *Scheduler) GetJob(ctx context.Context, id int) (*repo.ScheduledJob, error) {
+	var job repo.ScheduledJob
+	err := s.db.GetContext(ctx, &job, `SELECT * FROM scheduled_jobs WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetRuns retrieves a job's runs, newest first
+func (s *Scheduler) GetRuns(ctx context.Context, jobId int, limit int) ([]repo.JobRun, error) {
+	if _, err := s.GetJob(ctx, jobId); err != nil {
+		return nil, err
+	}
+	var runs []repo.JobRun
+	query := `SELECT * FROM job_runs WHERE job_id = $1 ORDER BY id DESC LIMIT $2`
+	if err := s.db.SelectContext(ctx, &runs, query, jobId, limit); err != nil {
+		return nil, fmt.Errorf("failed to get runs: %w", err)
+	}
+	return runs, nil
+}
+
+// Trigger queues a run of a job now, paused or not. The leader starts it on its next poll,
+// once any run in progress has finished.
+func (s *Scheduler) Trigger(ctx context.Context, jobId int, requestedBy *string) (*repo.JobRun, error) {
+	if _, err := s.GetJob(ctx, jobId); err != nil {
+		return nil, err
+	}
+	var run repo.JobRun
+	query := `
+		INSERT INTO job_runs (job_id, trigger, status, requested_by)
+		SELECT $1, 'manual', 'queued', $2
+		WHERE NOT EXISTS (SELECT 1 FROM job_runs WHERE job_id = $1 AND status = 'queued')
+		RETURNING *
+	`
+	err := s.db.GetContext(ctx, &run, query, jobId, requestedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRunAlreadyQueued
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue run: %w", err)
+	}
+	return &run, nil
+}
+
+// SetPaused pauses or resumes a job's schedule. A resumed job runs next at its next
+// scheduled time rather than making up the runs it skipped.
+func (s *Scheduler) SetPaused(ctx context.Context, jobId int, paused bool, updatedBy *string) (*repo.ScheduledJob, error) {
+	job, err := s.GetJob(ctx, jobId)
+	if err != nil {
+		return nil, err
+	}
+	next, err := nextRun(job.CronExpression, now())
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		UPDATE scheduled_jobs
+		SET is_paused = $2, next_run_at = CASE WHEN is_paused AND NOT $2 THEN $3 ELSE next_run_at END, updated_by = $4
+		WHERE id = $1
+		RETURNING *
+	`
+	if err = s.db.GetContext(ctx, job, query, jobId, paused, next, updatedBy); err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+	return job, nil
+}
+
+// SetSchedule changes a job's cron expression; it runs next at the first time the new
+// expression gives
+func (s *Scheduler) SetSchedule(ctx context.Context, jobId int, expression string, updatedBy *string) (*repo.ScheduledJob, error) {
+	expression = strings.TrimSpace(expression)
+	next, err := nextRun(expression, now())
+	if err != nil {
+		return nil, err
+	}
+	var job repo.ScheduledJob
+	query := `
+		UPDATE scheduled_jobs
+		SET cron_expression = $2, next_run_at = $3, updated_by = $4
+		WHERE id = $1
+		RETURNING *
+	`
+	err = s.db.GetContext(ctx, &job, query, jobId, expression, next, updatedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+	return &job, nil
+}
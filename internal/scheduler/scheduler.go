@@ -0,0 +1,399 @@
+// Package scheduler runs the background jobs on their cron schedules and records every run.
+// Schedules live in the database, so they can be changed, paused and triggered without a
+// redeploy. When several instances run, they elect a leader with a Postgres advisory lock and
+// only the leader runs jobs.
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrJobNotFound      = errors.New("job not found")
+	ErrInvalidCron      = errors.New("invalid cron expression")
+	ErrRunAlreadyQueued = errors.New("a manual run of the job is already queued")
+)
+
+const (
+	// DefaultPollInterval is how often the leader looks for due jobs and queued runs, and the
+	// other instances try to take over leadership
+	DefaultPollInterval = 15 * time.Second
+	// leaderLockKey is the advisory lock held by the instance running the jobs
+	leaderLockKey = 7_311_904_522
+)
+
+// JobFunc runs a job and returns a summary of what it did
+type JobFunc func(ctx context.Context) (string, error)
+
+// job is a job defined in code
+type job struct {
+	name        string
+	description string
+	cron        string
+	run         JobFunc
+}
+
+// Scheduler runs registered jobs while it is the leader
+type Scheduler struct {
+	db           *sqlx.DB
+	jobs         map[string]*job
+	instance     string
+	mutex        sync.Mutex
+	running      map[int]bool
+	leader       bool
+	wg           sync.WaitGroup
+	PollInterval time.Duration
+}
+
+func NewScheduler(db *sqlx.DB) *Scheduler {
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		db:           db,
+		jobs:         map[string]*job{},
+		instance:     fmt.Sprintf("%s:%d", hostname, os.Getpid()),
+		running:      map[int]bool{},
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// Register defines a job and its default cron expression, used until it is changed from the
+// admin. Register every job before Run.
+func (s *Scheduler) Register(name, description, cronExpression string, run JobFunc) {
+	s.jobs[name] = &job{name: name, description: description, cron: cronExpression, run: run}
+}
+
+// ParseCron reads a standard five-field cron expression, or a descriptor such as @daily.
+// Schedules are in UTC unless the expression starts with CRON_TZ=.
+func ParseCron(expression string) (cron.Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if !strings.HasPrefix(expression, "CRON_TZ=") && !strings.HasPrefix(expression, "TZ=") {
+		expression = "CRON_TZ=UTC " + expression
+	}
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCron, err)
+	}
+	return schedule, nil
+}
+
+// now is the current time as stored in the job tables, which hold UTC
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// nextRun is when a job runs next after t
+func nextRun(expression string, t time.Time) (*time.Time, error) {
+	schedule, err := ParseCron(expression)
+	if err != nil {
+		return nil, err
+	}
+	next := schedule.Next(t).UTC()
+	return &next, nil
+}
+
+// sync adds the registered jobs missing from the database
+func (s *Scheduler) sync(ctx context.Context) error {
+	for _, j := range s.jobs {
+		next, err := nextRun(j.cron, now())
+		if err != nil {
+			return fmt.Errorf("job %s: %w", j.name, err)
+		}
+		query := `
+			INSERT INTO scheduled_jobs (name, description, cron_expression, next_run_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description
+		`
+		if _, err = s.db.ExecContext(ctx, query, j.name, j.description, j.cron, next); err != nil {
+			return fmt.Errorf("failed to register job %s: %w", j.name, err)
+		}
+	}
+	return nil
+}
+
+// IsLeader reports whether this instance is running the jobs
+func (s *Scheduler) IsLeader() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.leader
+}
+
+// Run runs jobs until ctx is cancelled, whenever this instance holds leadership. Leadership
+// is an advisory lock held on a connection of its own, so it passes to another instance as
+// soon as this one stops or loses its connection. Runs in progress are cancelled when
+// leadership is lost and waited for before Run returns.
+func (s *Scheduler) Run(ctx context.Context) {
+	if err := s.sync(ctx); err != nil {
+		logging.LogError("Scheduler: %v", err)
+		return
+	}
+
+	var lead *leadership
+	stepDown := func() {
+		if lead != nil {
+			lead.release()
+			lead = nil
+		}
+		s.setLeader(false)
+	}
+	defer func() {
+		stepDown()
+		s.wg.Wait()
+	}()
+
+	poll := time.NewTicker(s.PollInterval)
+	defer poll.Stop()
+	for {
+		if lead == nil {
+			lead = s.elect(ctx)
+			if lead != nil {
+				s.setLeader(true)
+				logging.LogInfo("Scheduler: %s is now running the jobs", s.instance)
+				s.recover(ctx)
+			}
+		} else if _, err := lead.conn.ExecContext(ctx, `SELECT 1`); err != nil && ctx.Err() == nil {
+			logging.LogError("Scheduler: lost leadership: %v", err)
+			stepDown()
+		}
+
+		if lead != nil {
+			if err := s.runDue(lead.ctx); err != nil && ctx.Err() == nil {
+				logging.LogError("Scheduler: %v", err)
+			}
+			if err := s.runQueued(lead.ctx); err != nil && ctx.Err() == nil {
+				logging.LogError("Scheduler: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-poll.C:
+		}
+	}
+}
+
+// leadership is the leader lock held on its connection, and the context of the runs started
+// under it
+type leadership struct {
+	conn   *sql.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// release cancels the runs in progress and gives up the lock
+func (l *leadership) release() {
+	l.cancel()
+	l.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, leaderLockKey)
+	l.conn.Close()
+}
+
+func (s *Scheduler) setLeader(leader bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.leader = leader
+}
+
+// elect tries to take the leader lock, returning the leadership when it did
+func (s *Scheduler) elect(ctx context.Context) *leadership {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			logging.LogError("Scheduler: failed to get a connection for leader election: %v", err)
+		}
+		return nil
+	}
+	var locked bool
+	if err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, leaderLockKey).Scan(&locked); err != nil || !locked {
+		if err != nil && ctx.Err() == nil {
+			logging.LogError("Scheduler: leader election failed: %v", err)
+		}
+		conn.Close()
+		return nil
+	}
+	leaderCtx, cancel := context.WithCancel(ctx)
+	return &leadership{conn: conn, ctx: leaderCtx, cancel: cancel}
+}
+
+// recover fails the runs a previous leader left running when it went away
+func (s *Scheduler) recover(ctx context.Context) {
+	query := `
+		UPDATE job_runs
+		SET status = 'failed', error = 'interrupted: the instance running it stopped', finished_at = $1
+		WHERE status = 'running'
+	`
+	result, err := s.db.ExecContext(ctx, query, now())
+	if err != nil {
+		logging.LogError("Scheduler: failed to recover interrupted runs: %v", err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		logging.LogInfo("Scheduler: marked %d interrupted runs failed", n)
+	}
+}
+
+// runDue starts the unpaused jobs whose next run has come, moving them on to the run after.
+// A run missed while no instance was leading is made up once.
+func (s *Scheduler) runDue(ctx context.Context) error {
+	var due []repo.ScheduledJob
+	query := `SELECT * FROM scheduled_jobs WHERE NOT is_paused AND next_run_at <= $1 ORDER BY next_run_at`
+	if err := s.db.SelectContext(ctx, &due, query, now()); err != nil {
+		return fmt.Errorf("failed to get due jobs: %w", err)
+	}
+
+	for i := range due {
+		scheduled := &due[i]
+		j, ok := s.jobs[scheduled.Name]
+		if !ok || s.isRunning(scheduled.Id) {
+			continue
+		}
+		next, err := nextRun(scheduled.CronExpression, now())
+		if err != nil {
+			logging.LogError("Scheduler: job %s: %v", scheduled.Name, err)
+			continue
+		}
+
+		var runId int
+		err = s.withTx(ctx, func(tx *sqlx.Tx) error {
+			if _, err := tx.ExecContext(ctx, `UPDATE scheduled_jobs SET next_run_at = $2 WHERE id = $1`, scheduled.Id, next); err != nil {
+				return err
+			}
+			return tx.GetContext(ctx, &runId, `
+				INSERT INTO job_runs (job_id, trigger, status, instance, started_at)
+				VALUES ($1, 'schedule', 'running', $2, $3)
+				RETURNING id
+			`, scheduled.Id, s.instance, now())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to start job %s: %w", scheduled.Name, err)
+		}
+		s.start(ctx, scheduled.Id, runId, j)
+	}
+	return nil
+}
+
+// runQueued starts the manual runs waiting for a job that is not already running
+func (s *Scheduler) runQueued(ctx context.Context) error {
+	var queued []struct {
+		repo.JobRun
+		Name string `db:"name"`
+	}
+	query := `
+		SELECT r.*, j.name
+		FROM job_runs r
+		JOIN scheduled_jobs j ON j.id = r.job_id
+		WHERE r.status = 'queued'
+		ORDER BY r.id
+	`
+	if err := s.db.SelectContext(ctx, &queued, query); err != nil {
+		return fmt.Errorf("failed to get queued runs: %w", err)
+	}
+
+	for _, run := range queued {
+		if s.isRunning(run.JobId) {
+			continue
+		}
+		j, ok := s.jobs[run.Name]
+		if !ok {
+			s.finish(ctx, run.JobId, run.Id, now(), "", fmt.Errorf("job %s is not defined on %s", run.Name, s.instance))
+			continue
+		}
+		query := `
+			UPDATE job_runs
+			SET status = 'running', instance = $2, started_at = $3
+			WHERE id = $1 AND status = 'queued'
+		`
+		if _, err := s.db.ExecContext(ctx, query, run.Id, s.instance, now()); err != nil {
+			return fmt.Errorf("failed to start run %d: %w", run.Id, err)
+		}
+		s.start(ctx, run.JobId, run.Id, j)
+	}
+	return nil
+}
+
+func (s *Scheduler) isRunning(jobId int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.running[jobId]
+}
+
+// start runs a job in the background; a job never runs twice at once
+func (s *Scheduler) start(ctx context.Context, jobId, runId int, j *job) {
+	s.mutex.Lock()
+	s.running[jobId] = true
+	s.mutex.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mutex.Lock()
+			delete(s.running, jobId)
+			s.mutex.Unlock()
+		}()
+
+		logging.LogInfo("Scheduler: running job %s", j.name)
+		startedAt := now()
+		result, err := j.run(ctx)
+		if err != nil {
+			logging.LogError("Scheduler: job %s failed: %v", j.name, err)
+		} else {
+			logging.LogInfo("Scheduler: job %s succeeded: %s", j.name, result)
+		}
+		// Record the outcome even when leadership was lost mid-run
+		s.finish(context.Background(), jobId, runId, startedAt, result, err)
+	}()
+}
+
+// finish records how a run ended
+func (s *Scheduler) finish(ctx context.Context, jobId, runId int, startedAt time.Time, result string, runErr error) {
+	status := repo.JobRunStatusSucceeded
+	var errMessage *string
+	if runErr != nil {
+		status = repo.JobRunStatusFailed
+		message := runErr.Error()
+		errMessage = &message
+	}
+	finishedAt := now()
+
+	err := s.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE job_runs
+			SET status = $2, result = NULLIF($3, ''), error = $4, finished_at = $5, duration_ms = $6
+			WHERE id = $1
+		`, runId, status, result, errMessage, finishedAt, finishedAt.Sub(startedAt).Milliseconds())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE scheduled_jobs SET last_run_at = $2, last_status = $3 WHERE id = $1
+		`, jobId, startedAt, status)
+		return err
+	})
+	if err != nil {
+		logging.LogError("Scheduler: failed to record run %d: %v", runId, err)
+	}
+}
+
+func (s *Scheduler) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,23 +1,32 @@
 package adminSvc
 
 import (
+	"context"
 	"time"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type DashboardService struct {
-	db *sqlx.DB
+	db           *sqlx.DB
+	engine       *pricing.Engine
+	registry     *pricing.Registry
+	elasticities *pricing.ElasticityEstimator
 }
 
-func NewDashboardService(db *sqlx.DB) *DashboardService {
-	return &DashboardService{db: db}
+func NewDashboardService(db *sqlx.DB, engine *pricing.Engine, registry *pricing.Registry, elasticities *pricing.ElasticityEstimator) *DashboardService {
+	return &DashboardService{db: db, engine: engine, registry: registry, elasticities: elasticities}
 }
 
 // Data structures for dashboard responses
 type ModelPerformanceData struct {
 	ModelVersion   string    `json:"model_version" db:"model_version"`
+	Status         string    `json:"status" db:"status"`
 	TrainingDate   time.Time `json:"training_date" db:"training_date"`
 	RSquared       float64   `json:"r_squared" db:"r_squared"`
 	MSE            float64   `json:"mse" db:"mse"`
@@ -66,21 +75,35 @@ type CustomerBehavior struct {
 	SalesVelocity          float64 `json:"sales_velocity" db:"sales_velocity"`
 }
 
+// ElasticityData is a product's elasticity, taken from its category when Source is "category"
+type ElasticityData struct {
+	ProductID    int        `json:"product_id" db:"product_id"`
+	ProductName  string     `json:"product_name" db:"product_name"`
+	CategoryName string     `json:"category_name" db:"category_name"`
+	Elasticity   *float64   `json:"elasticity" db:"elasticity"`
+	Source       *string    `json:"source" db:"source"`
+	RSquared     *float64   `json:"r_squared" db:"r_squared"`
+	Observations *int       `json:"observations" db:"observations"`
+	EstimatedAt  *time.Time `json:"estimated_at" db:"estimated_at"`
+}
+
 type OperationalHealth struct {
 	Metric string  `json:"metric" db:"metric"`
 	Value  float64 `json:"value" db:"value"`
 	Status string  `json:"status" db:"status"`
 }
 
-// CoefficientData holds the latest regression model coefficients
+// CoefficientData holds the active regression model coefficients
 type CoefficientData struct {
 	ModelVersion                string    `json:"model_version" db:"model_version"`
+	Status                      string    `json:"status" db:"status"`
 	TrainingDate                time.Time `json:"training_date" db:"training_date"`
 	SampleSize                  int       `json:"sample_size" db:"sample_size"`
 	RSquared                    float64   `json:"r_squared" db:"r_squared"`
 	MSE                         float64   `json:"mse" db:"mse"`
 	RMSE                        float64   `json:"rmse" db:"rmse"`
 	MAE                         float64   `json:"mae" db:"mae"`
+	Intercept                   float64   `json:"intercept" db:"intercept"`
 	DaysSinceLastSaleCoef       float64   `json:"days_since_last_sale_coef" db:"days_since_last_sale_coef"`
 	SalesVelocityCoef           float64   `json:"sales_velocity_coef" db:"sales_velocity_coef"`
 	TotalSalesCountCoef         float64   `json:"total_sales_count_coef" db:"total_sales_count_coef"`
@@ -93,18 +116,20 @@ type CoefficientData struct {
 	UpdatedAt                   time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// GetCoefficients fetches the latest regression model coefficients (LIMIT 1)
+// GetCoefficients fetches the active regression model coefficients, falling back to the newest model when none is active
 func (s *DashboardService) GetCoefficients() (*CoefficientData, error) {
 	logging.LogInfo("DashboardService: GetCoefficients called")
 	query := `
 		SELECT 
 			model_version,
+			status,
 			training_date,
 			sample_size,
 			COALESCE(r_squared, 0.0) as r_squared,
 			COALESCE(mse, 0.0) as mse,
 			COALESCE(rmse, 0.0) as rmse,
 			COALESCE(mae, 0.0) as mae,
+			COALESCE(intercept, 0.0) as intercept,
 			COALESCE(days_since_last_sale_coef, 0.0) as days_since_last_sale_coef,
 			COALESCE(sales_velocity_coef, 0.0) as sales_velocity_coef,
 			COALESCE(total_sales_count_coef, 0.0) as total_sales_count_coef,
@@ -116,7 +141,7 @@ func (s *DashboardService) GetCoefficients() (*CoefficientData, error) {
 			created_at,
 			updated_at
 		FROM price_model_coefficients
-		ORDER BY training_date DESC
+		ORDER BY (status = 'active') DESC, training_date DESC
 		LIMIT 1
 	`
 	var coef CoefficientData
@@ -135,6 +160,7 @@ func (s *DashboardService) ViewModelPerformance() ([]ModelPerformanceData, error
 	query := `
 		SELECT 
 			model_version,
+			status,
 			training_date,
 			COALESCE(r_squared, 0.0) as r_squared,
 			COALESCE(mse, 0.0) as mse,
@@ -241,7 +267,7 @@ func (s *DashboardService) AnalyseDynamicPricing(days int) ([]PricingAnalytics,
 			pm.adjusted_price,
 			(pm.adjusted_price - pm.base_price) as price_change,
 			pa.created_at as last_adjusted,
-			pa.model_version
+			COALESCE(pa.model_version, pa.source, '') as model_version
 		FROM price_adjustments pa
 		JOIN products p ON pa.product_id = p.id
 		JOIN product_metrics pm ON pa.product_id = pm.product_id
@@ -258,6 +284,79 @@ func (s *DashboardService) AnalyseDynamicPricing(days int) ([]PricingAnalytics,
 	return results, err
 }
 
+// RunBacktest - Replay a date range through a registered model or ad-hoc coefficients.
+// With neither, the active model is used.
+func (s *DashboardService) RunBacktest(ctx context.Context, modelVersion string, coefficients *repo.PriceModelCoefficients, from, to time.Time, productIds []int) (*pricing.BacktestResult, error) {
+	logging.LogInfo("DashboardService: RunBacktest called with model=%q from=%s to=%s", modelVersion, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	var coef *repo.PriceModelCoefficients
+	var err error
+	switch {
+	case coefficients != nil:
+		coef = coefficients
+		if coef.ModelVersion == "" {
+			coef.ModelVersion = "ad-hoc"
+		}
+	case modelVersion != "":
+		coef, err = s.registry.Get(ctx, modelVersion)
+	default:
+		coef, err = s.registry.Active(ctx)
+	}
+	if err != nil {
+		logging.LogError("DashboardService: RunBacktest error: " + err.Error())
+		return nil, err
+	}
+
+	result, err := s.engine.Backtest(ctx, coef, from, to, productIds)
+	if err != nil {
+		logging.LogError("DashboardService: RunBacktest error: " + err.Error())
+		return nil, err
+	}
+	logging.LogInfo("DashboardService: RunBacktest success")
+	return result, nil
+}
+
+// GetElasticities - Price elasticity per product, most elastic first.
+// Products without an estimate of their own use their category's.
+func (s *DashboardService) GetElasticities() ([]ElasticityData, error) {
+	logging.LogInfo("DashboardService: GetElasticities called")
+	query := `
+		SELECT
+			p.id as product_id,
+			p.name as product_name,
+			c.name as category_name,
+			COALESCE(pe.elasticity, ce.elasticity) as elasticity,
+			COALESCE(pe.scope, ce.scope) as source,
+			COALESCE(pe.r_squared, ce.r_squared) as r_squared,
+			COALESCE(pe.observations, ce.observations) as observations,
+			COALESCE(pe.estimated_at, ce.estimated_at) as estimated_at
+		FROM products p
+		JOIN categories c ON p.category_id = c.id
+		LEFT JOIN price_elasticities pe ON pe.scope = 'product' AND pe.scope_id = p.id
+		LEFT JOIN price_elasticities ce ON ce.scope = 'category' AND ce.scope_id = p.category_id
+		ORDER BY COALESCE(pe.elasticity, ce.elasticity) ASC NULLS LAST, p.id
+	`
+	var results []ElasticityData
+	err := s.db.Select(&results, query)
+	if err != nil {
+		logging.LogError("DashboardService: GetElasticities error: " + err.Error())
+	} else {
+		logging.LogInfo("DashboardService: GetElasticities success")
+	}
+	return results, err
+}
+
+// EstimateElasticities - Recompute elasticities now instead of waiting for the nightly job
+func (s *DashboardService) EstimateElasticities(ctx context.Context, windowDays int) (*pricing.ElasticitySummary, error) {
+	logging.LogInfo("DashboardService: EstimateElasticities called with windowDays=%d", windowDays)
+	summary, err := s.elasticities.Estimate(ctx, windowDays)
+	if err != nil {
+		logging.LogError("DashboardService: EstimateElasticities error: " + err.Error())
+		return nil, err
+	}
+	logging.LogInfo("DashboardService: EstimateElasticities success")
+	return summary, nil
+}
+
 // AnalyseCustomerBehavior - Customer interaction patterns
 func (s *DashboardService) AnalyseCustomerBehavior() ([]CustomerBehavior, error) {
 	logging.LogInfo("DashboardService: AnalyseCustomerBehavior called")
@@ -295,10 +394,10 @@ func (s *DashboardService) ViewOperationalHealth() ([]OperationalHealth, error)
 			SELECT 
 				'Order Completion Rate' as metric,
 				ROUND(
-					(COUNT(CASE WHEN status = 'completed' THEN 1 END) * 100.0 / COUNT(*)), 2
+					(COUNT(CASE WHEN status = ANY($1) THEN 1 END) * 100.0 / COUNT(*)), 2
 				) as value,
 				CASE 
-					WHEN (COUNT(CASE WHEN status = 'completed' THEN 1 END) * 100.0 / COUNT(*)) >= 80 
+					WHEN (COUNT(CASE WHEN status = ANY($1) THEN 1 END) * 100.0 / COUNT(*)) >= 80 
 					THEN 'Good' 
 					ELSE 'Needs Attention' 
 				END as status
@@ -350,7 +449,7 @@ func (s *DashboardService) ViewOperationalHealth() ([]OperationalHealth, error)
 		) metrics
 	`
 	var results []OperationalHealth
-	err := s.db.Select(&results, query)
+	err := s.db.Select(&results, query, pq.Array(orders.Purchased))
 	if err != nil {
 		logging.LogError("DashboardService: ViewOperationalHealth error: " + err.Error())
 	} else {
@@ -0,0 +1,309 @@
+package adminSvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PriceExperimentService struct {
+	db       *sqlx.DB
+	registry *pricing.Registry
+}
+
+func NewPriceExperimentService(db *sqlx.DB, registry *pricing.Registry) *PriceExperimentService {
+	return &PriceExperimentService{db: db, registry: registry}
+}
+
+// ExperimentArmReport is the conversion and revenue of one experiment arm.
+// Conversion counts exposed customers who went on to buy an experiment product in that arm.
+type ExperimentArmReport struct {
+	Arm               repo.ExperimentArm `json:"arm" db:"arm"`
+	Exposed           int                `json:"exposed" db:"exposed"`
+	Converted         int                `json:"converted" db:"converted"`
+	ConversionRate    float64            `json:"conversion_rate" db:"-"`
+	Orders            int                `json:"orders" db:"orders"`
+	UnitsSold         int                `json:"units_sold" db:"units_sold"`
+	Revenue           float64            `json:"revenue" db:"revenue"`
+	RevenuePerExposed float64            `json:"revenue_per_exposed" db:"-"`
+	AveragePrice      float64            `json:"average_price" db:"-"`
+}
+
+// ExperimentReport compares the arms of an experiment
+type ExperimentReport struct {
+	Experiment *repo.PriceExperiment `json:"experiment"`
+	Arms       []ExperimentArmReport `json:"arms"`
+}
+
+// validateExperiment checks an experiment's name, split and variant
+func (s *PriceExperimentService) validateExperiment(ctx context.Context, experiment *repo.PriceExperiment) error {
+	if strings.TrimSpace(experiment.Name) == "" {
+		return errors.New("name is required")
+	}
+	if experiment.TreatmentPct <= 0 || experiment.TreatmentPct >= 100 {
+		return errors.New("treatment_pct must be between 0 and 100")
+	}
+	if len(experiment.ProductIds) == 0 {
+		return errors.New("product_ids are required")
+	}
+
+	switch experiment.VariantType {
+	case repo.ExperimentVariantMultiplier:
+		if experiment.PriceMultiplier == nil || *experiment.PriceMultiplier <= 0 {
+			return errors.New("price_multiplier must be positive")
+		}
+		experiment.ModelVersion = nil
+	case repo.ExperimentVariantModel:
+		if experiment.ModelVersion == nil || *experiment.ModelVersion == "" {
+			return errors.New("model_version is required")
+		}
+		if _, err := s.registry.Get(ctx, *experiment.ModelVersion); err != nil {
+			return err
+		}
+		experiment.PriceMultiplier = nil
+	default:
+		return fmt.Errorf("invalid variant_type: %q", experiment.VariantType)
+	}
+	return nil
+}
+
+// CreateExperiment starts an experiment on a set of products.
+// A product can only be in one running experiment at a time.
+func (s *PriceExperimentService) CreateExperiment(ctx context.Context, experiment *repo.PriceExperiment) (*repo.PriceExperiment, error) {
+	if err := s.validateExperiment(ctx, experiment); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the products so two experiments cannot claim the same product at once
+	var found []int
+	err = tx.SelectContext(ctx, &found, `SELECT id FROM products WHERE id = ANY($1::int[]) FOR UPDATE`, pq.Array(experiment.ProductIds))
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(uniqueIds(experiment.ProductIds)) {
+		return nil, errors.New("one or more products not found")
+	}
+
+	var conflict struct {
+		ProductId    int `db:"product_id"`
+		ExperimentId int `db:"experiment_id"`
+	}
+	conflictQuery := `
+		SELECT ep.product_id, ep.experiment_id
+		FROM price_experiment_products ep
+		JOIN price_experiments e ON e.id = ep.experiment_id
+		WHERE e.status = 'running' AND ep.product_id = ANY($1::int[])
+		LIMIT 1
+	`
+	err = tx.GetContext(ctx, &conflict, conflictQuery, pq.Array(experiment.ProductIds))
+	if err == nil {
+		return nil, fmt.Errorf("product %d is already in running experiment %d", conflict.ProductId, conflict.ExperimentId)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO price_experiments (name, description, variant_type, price_multiplier, model_version, treatment_pct, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING *
+	`
+	productIds := uniqueIds(experiment.ProductIds)
+	err = tx.GetContext(ctx, experiment, query, experiment.Name, experiment.Description, experiment.VariantType,
+		experiment.PriceMultiplier, experiment.ModelVersion, experiment.TreatmentPct, experiment.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create price experiment: %w", err)
+	}
+
+	productsQuery := `
+		INSERT INTO price_experiment_products (experiment_id, product_id)
+		SELECT $1, UNNEST($2::int[])
+	`
+	if _, err = tx.ExecContext(ctx, productsQuery, experiment.Id, pq.Array(productIds)); err != nil {
+		return nil, fmt.Errorf("failed to add experiment products: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	experiment.ProductIds = productIds
+	return experiment, nil
+}
+
+// loadProductIds fills in the product ids of each experiment
+func (s *PriceExperimentService) loadProductIds(ctx context.Context, experiments ...*repo.PriceExperiment) error {
+	if len(experiments) == 0 {
+		return nil
+	}
+	ids := make([]int, len(experiments))
+	byId := map[int]*repo.PriceExperiment{}
+	for i, experiment := range experiments {
+		ids[i] = experiment.Id
+		experiment.ProductIds = []int{}
+		byId[experiment.Id] = experiment
+	}
+
+	var rows []struct {
+		ExperimentId int `db:"experiment_id"`
+		ProductId    int `db:"product_id"`
+	}
+	query := `
+		SELECT experiment_id, product_id
+		FROM price_experiment_products
+		WHERE experiment_id = ANY($1::int[])
+		ORDER BY experiment_id, product_id
+	`
+	if err := s.db.SelectContext(ctx, &rows, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		byId[row.ExperimentId].ProductIds = append(byId[row.ExperimentId].ProductIds, row.ProductId)
+	}
+	return nil
+}
+
+// GetExperiments retrieves experiments by status (all when empty), newest first
+func (s *PriceExperimentService) GetExperiments(ctx context.Context, status repo.ExperimentStatus) ([]*repo.PriceExperiment, error) {
+	var experiments []*repo.PriceExperiment
+	query := `
+		SELECT *
+		FROM price_experiments
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY started_at DESC
+	`
+	if err := s.db.SelectContext(ctx, &experiments, query, status); err != nil {
+		return nil, err
+	}
+	if err := s.loadProductIds(ctx, experiments...); err != nil {
+		return nil, err
+	}
+	return experiments, nil
+}
+
+// GetExperimentByID retrieves an experiment by its ID
+func (s *PriceExperimentService) GetExperimentByID(ctx context.Context, id int) (*repo.PriceExperiment, error) {
+	var experiment repo.PriceExperiment
+	err := s.db.GetContext(ctx, &experiment, `SELECT * FROM price_experiments WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pricing.ErrExperimentNotFound
+		}
+		return nil, err
+	}
+	if err = s.loadProductIds(ctx, &experiment); err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// StopExperiment ends an experiment; its products go back to the live price for everyone
+func (s *PriceExperimentService) StopExperiment(ctx context.Context, id int) (*repo.PriceExperiment, error) {
+	var experiment repo.PriceExperiment
+	query := `
+		UPDATE price_experiments
+		SET status = 'stopped', stopped_at = NOW()
+		WHERE id = $1 AND status = 'running'
+		RETURNING *
+	`
+	err := s.db.GetContext(ctx, &experiment, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err = s.GetExperimentByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, pricing.ErrExperimentStopped
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = s.loadProductIds(ctx, &experiment); err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// GetExperimentReport reports conversion and revenue per arm. Revenue comes from the sales
+// of order items the experiment priced.
+func (s *PriceExperimentService) GetExperimentReport(ctx context.Context, id int) (*ExperimentReport, error) {
+	experiment, err := s.GetExperimentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ExperimentReport{Experiment: experiment}
+	query := `
+		WITH arms AS (
+			SELECT UNNEST(ARRAY['control', 'treatment']) AS arm
+		),
+		exposed AS (
+			SELECT arm, COUNT(*) AS exposed
+			FROM price_experiment_exposures
+			WHERE experiment_id = $1
+			GROUP BY arm
+		),
+		sold AS (
+			SELECT
+				oi.experiment_arm AS arm,
+				COUNT(DISTINCT o.customer_id) AS converted,
+				COUNT(DISTINCT o.id) AS orders,
+				SUM(s.quantity) AS units_sold,
+				SUM(s.sale_price * s.quantity) AS revenue
+			FROM sales s
+			JOIN order_items oi ON oi.id = s.order_item_id
+			JOIN orders o ON o.id = oi.order_id
+			WHERE oi.experiment_id = $1
+			GROUP BY oi.experiment_arm
+		)
+		SELECT
+			a.arm,
+			COALESCE(e.exposed, 0) AS exposed,
+			COALESCE(sd.converted, 0) AS converted,
+			COALESCE(sd.orders, 0) AS orders,
+			COALESCE(sd.units_sold, 0) AS units_sold,
+			COALESCE(sd.revenue, 0) AS revenue
+		FROM arms a
+		LEFT JOIN exposed e ON e.arm = a.arm
+		LEFT JOIN sold sd ON sd.arm = a.arm
+		ORDER BY a.arm
+	`
+	if err = s.db.SelectContext(ctx, &report.Arms, query, id); err != nil {
+		return nil, fmt.Errorf("failed to get experiment report: %w", err)
+	}
+
+	for i := range report.Arms {
+		arm := &report.Arms[i]
+		if arm.Exposed > 0 {
+			arm.ConversionRate = float64(arm.Converted) / float64(arm.Exposed)
+			arm.RevenuePerExposed = arm.Revenue / float64(arm.Exposed)
+		}
+		if arm.UnitsSold > 0 {
+			arm.AveragePrice = arm.Revenue / float64(arm.UnitsSold)
+		}
+	}
+	return report, nil
+}
+
+// uniqueIds returns ids without duplicates, in their original order
+func uniqueIds(ids []int) []int {
+	seen := map[int]bool{}
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,52 @@
+package adminSvc
+
+import (
+	"context"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/scheduler"
+)
+
+// JobService manages the background jobs: their schedules, run history and manual runs
+type JobService struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewJobService(scheduler *scheduler.Scheduler) *JobService {
+	return &JobService{scheduler: scheduler}
+}
+
+// GetJobs retrieves every job with its schedule and latest outcome
+func (s *JobService) GetJobs(ctx context.Context) ([]repo.ScheduledJob, error) {
+	return s.scheduler.GetJobs(ctx)
+}
+
+// GetJobByID retrieves a job by its ID
+func (s *JobService) GetJobByID(ctx context.Context, id int) (*repo.ScheduledJob, error) {
+	return s.scheduler.GetJob(ctx, id)
+}
+
+// GetRuns retrieves a job's run history, newest first
+func (s *JobService) GetRuns(ctx context.Context, id int, limit int) ([]repo.JobRun, error) {
+	return s.scheduler.GetRuns(ctx, id, limit)
+}
+
+// TriggerJob queues a run of a job now
+func (s *JobService) TriggerJob(ctx context.Context, id int, requestedBy *string) (*repo.JobRun, error) {
+	return s.scheduler.Trigger(ctx, id, requestedBy)
+}
+
+// PauseJob stops a job's scheduled runs until it is resumed
+func (s *JobService) PauseJob(ctx context.Context, id int, updatedBy *string) (*repo.ScheduledJob, error) {
+	return s.scheduler.SetPaused(ctx, id, true, updatedBy)
+}
+
+// ResumeJob restarts a paused job's scheduled runs
+func (s *JobService) ResumeJob(ctx context.Context, id int, updatedBy *string) (*repo.ScheduledJob, error) {
+	return s.scheduler.SetPaused(ctx, id, false, updatedBy)
+}
+
+// UpdateSchedule changes a job's cron expression
+func (s *JobService) UpdateSchedule(ctx context.Context, id int, expression string, updatedBy *string) (*repo.ScheduledJob, error) {
+	return s.scheduler.SetSchedule(ctx, id, expression, updatedBy)
+}
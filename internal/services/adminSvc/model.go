@@ -0,0 +1,90 @@
+package adminSvc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+type ModelRegistryService struct {
+	registry *pricing.Registry
+	engine   *pricing.Engine
+}
+
+func NewModelRegistryService(registry *pricing.Registry, engine *pricing.Engine) *ModelRegistryService {
+	return &ModelRegistryService{registry: registry, engine: engine}
+}
+
+// ShadowReport is the logged comparison between a shadow model and the active model
+type ShadowReport struct {
+	Summary     *pricing.ShadowSummary  `json:"summary"`
+	Predictions []repo.ShadowPrediction `json:"predictions"`
+}
+
+// GetModels retrieves every registered model, newest first
+func (s *ModelRegistryService) GetModels(ctx context.Context) ([]repo.PriceModelCoefficients, error) {
+	return s.registry.List(ctx)
+}
+
+// GetModel retrieves a model by its version
+func (s *ModelRegistryService) GetModel(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	return s.registry.Get(ctx, version)
+}
+
+// PromoteModel makes a model the active one
+func (s *ModelRegistryService) PromoteModel(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	return s.registry.Promote(ctx, version)
+}
+
+// RollbackModel reactivates the model that was active before the current one
+func (s *ModelRegistryService) RollbackModel(ctx context.Context) (*repo.PriceModelCoefficients, error) {
+	return s.registry.Rollback(ctx)
+}
+
+// ShadowModel puts a model in shadow mode
+func (s *ModelRegistryService) ShadowModel(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	return s.registry.StartShadow(ctx, version)
+}
+
+// RetireModel takes a candidate or shadow model out of use
+func (s *ModelRegistryService) RetireModel(ctx context.Context, version string) (*repo.PriceModelCoefficients, error) {
+	return s.registry.Retire(ctx, version)
+}
+
+// TrainModel trains a candidate model on the feature snapshots captured in [from, to)
+func (s *ModelRegistryService) TrainModel(ctx context.Context, from, to time.Time) (*repo.PriceModelCoefficients, error) {
+	return s.engine.TrainWindow(ctx, from, to)
+}
+
+// GetTrainingSet retrieves the feature snapshots a model was trained on
+func (s *ModelRegistryService) GetTrainingSet(ctx context.Context, version string) ([]repo.PricingFeatureSnapshot, error) {
+	model, err := s.registry.Get(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if model.TrainingWindowStart == nil || model.TrainingWindowEnd == nil {
+		return nil, errors.New("model was trained before feature snapshots were recorded")
+	}
+	return s.engine.TrainingSet(ctx, *model.TrainingWindowStart, *model.TrainingWindowEnd)
+}
+
+// GetShadowReport retrieves the logged price differences for a shadow model
+func (s *ModelRegistryService) GetShadowReport(ctx context.Context, version string, limit int) (*ShadowReport, error) {
+	if _, err := s.registry.Get(ctx, version); err != nil {
+		return nil, err
+	}
+	summary, predictions, err := s.registry.ShadowReport(ctx, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ShadowReport{Summary: summary, Predictions: predictions}, nil
+}
+
+// ExplainPrice breaks a product's price down into the active model's terms, with the
+// policy, elasticity and schedule state around it
+func (s *ModelRegistryService) ExplainPrice(ctx context.Context, productId int) (*pricing.PriceExplanationDetail, error) {
+	return s.engine.ExplainDetail(ctx, productId)
+}
@@ -0,0 +1,103 @@
+package adminSvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrNotFulfilmentStatus is a status admins cannot set by hand: orders are paid at checkout,
+// and cancelled or failed along with their cart, by the customer or when they expire
+var ErrNotFulfilmentStatus = errors.New("status must be processing, shipped, delivered or refunded")
+
+// fulfilmentStatuses are the statuses admins move orders to as they fulfil or refund them
+var fulfilmentStatuses = map[repo.OrderStatus]bool{
+	repo.OrderStatusProcessing: true,
+	repo.OrderStatusShipped:    true,
+	repo.OrderStatusDelivered:  true,
+	repo.OrderStatusRefunded:   true,
+}
+
+type OrderService struct {
+	db *sqlx.DB
+}
+
+func NewOrderService(db *sqlx.DB) *OrderService {
+	return &OrderService{db: db}
+}
+
+// GetOrders retrieves a page of every customer's orders matching the filter, newest first
+func (s *OrderService) GetOrders(ctx context.Context, filter repo.OrderFilter) (*repo.OrderPage, error) {
+	page := &repo.OrderPage{Orders: []repo.OrderSummary{}, Page: filter.Page, PageSize: filter.PageSize}
+
+	where := `
+		WHERE ($1 = 0 OR o.customer_id = $1) AND ($2 = '' OR o.status = $2)
+		AND ($3::timestamp IS NULL OR o.created_at >= $3)
+		AND ($4::timestamp IS NULL OR o.created_at < $4)
+	`
+	err := s.db.GetContext(ctx, &page.Total, `SELECT COUNT(*) FROM orders o`+where,
+		filter.CustomerId, filter.Status, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	query := `
+		SELECT o.id, o.customer_id, c.username AS customer_username, o.total_price, o.status,
+			o.price_valid_until, o.created_at, o.updated_at,
+			(SELECT COUNT(*) FROM order_items oi WHERE oi.order_id = o.id) AS item_count
+		FROM orders o
+		JOIN customers c ON c.id = o.customer_id
+	` + where + `
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $5 OFFSET $6
+	`
+	err = s.db.SelectContext(ctx, &page.Orders, query, filter.CustomerId, filter.Status, filter.From, filter.To,
+		filter.PageSize, (filter.Page-1)*filter.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	return page, nil
+}
+
+// GetOrderByID retrieves an order with its items, payments and status history
+func (s *OrderService) GetOrderByID(ctx context.Context, id int) (*repo.OrderWithItems, error) {
+	return orders.Detail(ctx, s.db, id)
+}
+
+// GetOrderHistory retrieves an order's status changes, oldest first
+func (s *OrderService) GetOrderHistory(ctx context.Context, id int) ([]repo.OrderStatusChange, error) {
+	var exists bool
+	if err := s.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM orders WHERE id = $1)`, id); err != nil {
+		return nil, fmt.Errorf("failed to get order %d: %w", id, err)
+	}
+	if !exists {
+		return nil, orders.ErrOrderNotFound
+	}
+	return orders.History(ctx, s.db, id)
+}
+
+// UpdateOrderStatus moves an order along fulfilment, or refunds it, on behalf of an admin
+func (s *OrderService) UpdateOrderStatus(ctx context.Context, id int, status repo.OrderStatus, username string, reason string) (*repo.Order, error) {
+	if !fulfilmentStatuses[status] {
+		return nil, ErrNotFulfilmentStatus
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	order, err := orders.Transition(ctx, tx, id, status, orders.Admin(username), reason)
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return order, nil
+}
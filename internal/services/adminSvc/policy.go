@@ -0,0 +1,171 @@
+package adminSvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+type PricingPolicyService struct {
+	db    *sqlx.DB
+	guard *pricing.Guard
+}
+
+func NewPricingPolicyService(db *sqlx.DB, guard *pricing.Guard) *PricingPolicyService {
+	return &PricingPolicyService{db: db, guard: guard}
+}
+
+// validatePolicy checks a policy's scope, target and limits
+func (s *PricingPolicyService) validatePolicy(ctx context.Context, policy *repo.PricingPolicy) error {
+	var table string
+	switch policy.Scope {
+	case repo.PolicyScopeProduct:
+		table = "products"
+	case repo.PolicyScopeCategory:
+		table = "categories"
+	case repo.PolicyScopeBrand:
+		table = "brands"
+	default:
+		return fmt.Errorf("invalid scope: %q", policy.Scope)
+	}
+
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM `+table+` WHERE id = $1)`, policy.ScopeId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s %d not found", policy.Scope, policy.ScopeId)
+	}
+
+	for _, limit := range []*float64{policy.FloorPrice, policy.CeilingPrice, policy.MaxChangePct, policy.MaxDailyChangePct, policy.MinMarginPct} {
+		if limit != nil && *limit < 0 {
+			return errors.New("policy limits cannot be negative")
+		}
+	}
+	if policy.FloorPrice != nil && policy.CeilingPrice != nil && *policy.FloorPrice > *policy.CeilingPrice {
+		return errors.New("floor price cannot be above the ceiling price")
+	}
+	return nil
+}
+
+// CreatePolicy creates a new pricing policy
+func (s *PricingPolicyService) CreatePolicy(ctx context.Context, policy *repo.PricingPolicy) (*repo.PricingPolicy, error) {
+	if err := s.validatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO pricing_policies (scope, scope_id, floor_price, ceiling_price, max_change_pct, max_daily_change_pct, min_margin_pct, is_active)
+		VALUES (:scope, :scope_id, :floor_price, :ceiling_price, :max_change_pct, :max_daily_change_pct, :min_margin_pct, :is_active)
+		RETURNING id, created_at, updated_at
+	`
+	rows, err := s.db.NamedQueryContext(ctx, query, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pricing policy: %w", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err = rows.Scan(&policy.Id, &policy.CreatedAt, &policy.UpdatedAt); err != nil {
+			return nil, err
+		}
+	}
+	return policy, rows.Err()
+}
+
+// GetPolicyByID retrieves a pricing policy by its ID
+func (s *PricingPolicyService) GetPolicyByID(ctx context.Context, id int) (*repo.PricingPolicy, error) {
+	var policy repo.PricingPolicy
+	query := `
+		SELECT *
+		FROM pricing_policies
+		WHERE id = $1
+	`
+	err := s.db.GetContext(ctx, &policy, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("pricing policy not found")
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// GetAllPolicies retrieves all pricing policies
+func (s *PricingPolicyService) GetAllPolicies(ctx context.Context) ([]*repo.PricingPolicy, error) {
+	var policies []*repo.PricingPolicy
+	query := `
+		SELECT *
+		FROM pricing_policies
+		ORDER BY scope, scope_id
+	`
+	err := s.db.SelectContext(ctx, &policies, query)
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// UpdatePolicy updates the limits of an existing pricing policy
+func (s *PricingPolicyService) UpdatePolicy(ctx context.Context, policy *repo.PricingPolicy) (*repo.PricingPolicy, error) {
+	if err := s.validatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE pricing_policies
+		SET scope = :scope, scope_id = :scope_id, floor_price = :floor_price, ceiling_price = :ceiling_price,
+			max_change_pct = :max_change_pct, max_daily_change_pct = :max_daily_change_pct,
+			min_margin_pct = :min_margin_pct, is_active = :is_active
+		WHERE id = :id
+	`
+	result, err := s.db.NamedExecContext(ctx, query, policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pricing policy: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, errors.New("pricing policy not found")
+	}
+	return s.GetPolicyByID(ctx, policy.Id)
+}
+
+// DeletePolicy deletes a pricing policy
+func (s *PricingPolicyService) DeletePolicy(ctx context.Context, id int) error {
+	query := `
+		DELETE FROM pricing_policies
+		WHERE id = $1
+	`
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// GetEffectivePolicy resolves the limits that apply to a product
+func (s *PricingPolicyService) GetEffectivePolicy(ctx context.Context, productId int) (*pricing.EffectivePolicy, error) {
+	return s.guard.EffectivePolicy(ctx, s.db, productId)
+}
+
+// GetRejections retrieves the most recent rejected price changes, optionally for one product
+func (s *PricingPolicyService) GetRejections(ctx context.Context, productId int, limit int) ([]*repo.PriceAdjustmentRejection, error) {
+	var rejections []*repo.PriceAdjustmentRejection
+	query := `
+		SELECT *
+		FROM price_adjustment_rejections
+		WHERE $1 = 0 OR product_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	err := s.db.SelectContext(ctx, &rejections, query, productId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return rejections, nil
+}
@@ -6,21 +6,29 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	ErrNegativeCost    = errors.New("cost price cannot be negative")
+	ErrProductNotFound = errors.New("product not found")
+)
+
 type ProductService struct {
     db              *sqlx.DB
     categoryService *CategoryService
     brandService    *BrandService
+    guard           *pricing.Guard
 }
 
-func NewProductService(db *sqlx.DB, categoryService *CategoryService, brandService *BrandService) *ProductService {
+func NewProductService(db *sqlx.DB, categoryService *CategoryService, brandService *BrandService, guard *pricing.Guard) *ProductService {
     return &ProductService{
         db:              db,
         categoryService: categoryService,
         brandService:    brandService,
+        guard:           guard,
     }
 }
 
@@ -260,12 +268,43 @@ func (s *ProductService) ChangeBasePrice(ctx context.Context, id int, price floa
     }
     defer tx.Rollback()
 
-    // Get Product
-    _, err = s.GetProductByID(ctx, id)
+    // Get current base price, locking the row until the change is committed
+    var oldPrice float64
+    getPriceQuery := `
+        SELECT base_price
+        FROM product_metrics
+        WHERE product_id = $1
+        FOR UPDATE
+    `
+    err = tx.GetContext(ctx, &oldPrice, getPriceQuery, id)
     if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return fmt.Errorf("failed to get product: %w", errors.New("product not found"))
+        }
         return fmt.Errorf("failed to get product: %w", err)
     }
 
+    // Check the new price against the pricing policies
+    change := pricing.PriceChange{
+        ProductId: id,
+        PriceType: repo.PriceTypeBase,
+        OldPrice:  oldPrice,
+        NewPrice:  price,
+        Source:    pricing.SourceManual,
+    }
+    err = s.guard.Check(ctx, tx, change)
+    var violation *pricing.PolicyViolation
+    if errors.As(err, &violation) {
+        // Recorded outside the transaction, which is rolled back
+        if rejectErr := s.guard.Reject(ctx, s.db, change, violation.Reason); rejectErr != nil {
+            return rejectErr
+        }
+        return violation
+    }
+    if err != nil {
+        return err
+    }
+
     // Update base price
     updatePriceQuery := `
         UPDATE product_metrics
@@ -277,6 +316,16 @@ func (s *ProductService) ChangeBasePrice(ctx context.Context, id int, price floa
         return fmt.Errorf("failed to update base price: %w", err)
     }
 
+    // Log the change, which shows in the price history while the product has no adjusted price
+    insertLogQuery := `
+        INSERT INTO price_adjustments (product_id, old_price, new_price, source, price_type)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+    _, err = tx.ExecContext(ctx, insertLogQuery, id, oldPrice, price, pricing.SourceManual, repo.PriceTypeBase)
+    if err != nil {
+        return fmt.Errorf("failed to log price adjustment: %w", err)
+    }
+
     // Commit transaction
     if err = tx.Commit(); err != nil {
         return fmt.Errorf("failed to commit transaction: %w", err)
@@ -285,6 +334,31 @@ func (s *ProductService) ChangeBasePrice(ctx context.Context, id int, price floa
     return nil
 }
 
+// ChangeCostPrice sets the unit cost used by the minimum margin policy
+func (s *ProductService) ChangeCostPrice(ctx context.Context, id int, costPrice float64) error {
+    if costPrice < 0 {
+        return ErrNegativeCost
+    }
+
+    query := `
+        UPDATE product_metrics
+        SET cost_price = $1
+        WHERE product_id = $2
+    `
+    result, err := s.db.ExecContext(ctx, query, costPrice, id)
+    if err != nil {
+        return fmt.Errorf("failed to update cost price: %w", err)
+    }
+    rows, err := result.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if rows == 0 {
+        return ErrProductNotFound
+    }
+    return nil
+}
+
 func (s *ProductService) RestockProduct(ctx context.Context, stock *repo.Stock) error {
 	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -0,0 +1,101 @@
+package adminSvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+type PricingProposalService struct {
+	db    *sqlx.DB
+	queue *pricing.ProposalQueue
+}
+
+func NewPricingProposalService(db *sqlx.DB, queue *pricing.ProposalQueue) *PricingProposalService {
+	return &PricingProposalService{db: db, queue: queue}
+}
+
+// ProposalResult is the outcome of reviewing one proposal in a bulk request
+type ProposalResult struct {
+	Id       int                 `json:"id"`
+	Proposal *repo.PriceProposal `json:"proposal,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// GetProposals retrieves proposals by status (all when empty), optionally for one product
+func (s *PricingProposalService) GetProposals(ctx context.Context, status repo.ProposalStatus, productId int, limit int) ([]*repo.PriceProposal, error) {
+	var proposals []*repo.PriceProposal
+	query := `
+		SELECT *
+		FROM price_proposals
+		WHERE ($1 = '' OR status = $1)
+		AND ($2 = 0 OR product_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	err := s.db.SelectContext(ctx, &proposals, query, status, productId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return proposals, nil
+}
+
+// GetProposalByID retrieves a proposal by its ID
+func (s *PricingProposalService) GetProposalByID(ctx context.Context, id int) (*repo.PriceProposal, error) {
+	var proposal repo.PriceProposal
+	query := `
+		SELECT *
+		FROM price_proposals
+		WHERE id = $1
+	`
+	err := s.db.GetContext(ctx, &proposal, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pricing.ErrProposalNotFound
+		}
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// ApproveProposal applies a pending proposal to the live price
+func (s *PricingProposalService) ApproveProposal(ctx context.Context, id int, reviewer string) (*repo.PriceProposal, error) {
+	return s.queue.Approve(ctx, id, reviewer)
+}
+
+// RejectProposal discards a pending proposal
+func (s *PricingProposalService) RejectProposal(ctx context.Context, id int, reviewer, note string) (*repo.PriceProposal, error) {
+	return s.queue.Reject(ctx, id, reviewer, note)
+}
+
+// ApproveProposals approves each proposal independently, so one failure does not block the rest
+func (s *PricingProposalService) ApproveProposals(ctx context.Context, ids []int, reviewer string) []ProposalResult {
+	results := make([]ProposalResult, 0, len(ids))
+	for _, id := range ids {
+		proposal, err := s.queue.Approve(ctx, id, reviewer)
+		results = append(results, proposalResult(id, proposal, err))
+	}
+	return results
+}
+
+// RejectProposals rejects each proposal independently
+func (s *PricingProposalService) RejectProposals(ctx context.Context, ids []int, reviewer, note string) []ProposalResult {
+	results := make([]ProposalResult, 0, len(ids))
+	for _, id := range ids {
+		proposal, err := s.queue.Reject(ctx, id, reviewer, note)
+		results = append(results, proposalResult(id, proposal, err))
+	}
+	return results
+}
+
+func proposalResult(id int, proposal *repo.PriceProposal, err error) ProposalResult {
+	result := ProposalResult{Id: id, Proposal: proposal}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package adminSvc
+
+import (
+	"context"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+)
+
+// RepricingJobService manages the repricing work queued by sales. A job that keeps failing
+// is dead-lettered, and waits here to be retried or discarded.
+type RepricingJobService struct {
+	jobs     *outbox.Store
+	repricer *pricing.Repricer
+}
+
+func NewRepricingJobService(jobs *outbox.Store, repricer *pricing.Repricer) *RepricingJobService {
+	return &RepricingJobService{jobs: jobs, repricer: repricer}
+}
+
+// GetJobs retrieves repricing jobs by status, newest first
+func (s *RepricingJobService) GetJobs(ctx context.Context, status repo.OutboxStatus, limit int) ([]repo.OutboxEvent, error) {
+	return s.jobs.List(ctx, status, limit)
+}
+
+// RetryJob queues a dead-lettered job again
+func (s *RepricingJobService) RetryJob(ctx context.Context, id int) (*repo.OutboxEvent, error) {
+	return s.jobs.Retry(ctx, id)
+}
+
+// DiscardJob drops a dead-lettered job
+func (s *RepricingJobService) DiscardJob(ctx context.Context, id int) (*repo.OutboxEvent, error) {
+	return s.jobs.Discard(ctx, id)
+}
+
+// GetStats retrieves the sale repricing counters since the server started
+func (s *RepricingJobService) GetStats() pricing.RepricerStats {
+	return s.repricer.Stats()
+}
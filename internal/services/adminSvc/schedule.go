@@ -0,0 +1,129 @@
+package adminSvc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+type PriceScheduleService struct {
+	db        *sqlx.DB
+	schedules *pricing.PriceSchedules
+}
+
+func NewPriceScheduleService(db *sqlx.DB, schedules *pricing.PriceSchedules) *PriceScheduleService {
+	return &PriceScheduleService{db: db, schedules: schedules}
+}
+
+// validateSchedule checks a schedule's product, price and window
+func (s *PriceScheduleService) validateSchedule(ctx context.Context, schedule *repo.PriceSchedule) error {
+	switch schedule.PriceType {
+	case repo.PriceTypeBase, repo.PriceTypeAdjusted:
+	default:
+		return fmt.Errorf("invalid price_type: %q", schedule.PriceType)
+	}
+	if schedule.Price <= 0 {
+		return errors.New("price must be positive")
+	}
+	if schedule.StartsAt.IsZero() {
+		return errors.New("starts_at is required")
+	}
+	if schedule.EndsAt != nil && !schedule.EndsAt.After(schedule.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	var exists bool
+	err := s.db.GetContext(ctx, &exists, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)`, schedule.ProductId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("product %d not found", schedule.ProductId)
+	}
+
+	// A temporary change restores the price it replaced, so it cannot share its window
+	// with another change to the same price
+	var overlapping int
+	overlapQuery := `
+		SELECT id
+		FROM price_schedules
+		WHERE product_id = $1 AND price_type = $2 AND status IN ('scheduled', 'active')
+		AND (ends_at IS NOT NULL OR $4::timestamptz IS NOT NULL)
+		AND tsrange(starts_at, ends_at) && tsrange($3::timestamptz::timestamp, $4::timestamptz::timestamp)
+		LIMIT 1
+	`
+	err = s.db.GetContext(ctx, &overlapping, overlapQuery, schedule.ProductId, schedule.PriceType, schedule.StartsAt, schedule.EndsAt)
+	if err == nil {
+		return fmt.Errorf("overlaps price schedule %d for the same product and price", overlapping)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return nil
+}
+
+// CreateSchedule schedules a price change; with ends_at set the change is temporary
+func (s *PriceScheduleService) CreateSchedule(ctx context.Context, schedule *repo.PriceSchedule) (*repo.PriceSchedule, error) {
+	if err := s.validateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	// Timestamps are cast through timestamptz so their offset is honoured
+	query := `
+		INSERT INTO price_schedules (product_id, price_type, price, starts_at, ends_at, note, created_by)
+		VALUES ($1, $2, $3, $4::timestamptz, $5::timestamptz, $6, $7)
+		RETURNING *
+	`
+	var created repo.PriceSchedule
+	err := s.db.GetContext(ctx, &created, query, schedule.ProductId, schedule.PriceType, schedule.Price,
+		schedule.StartsAt, schedule.EndsAt, schedule.Note, schedule.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create price schedule: %w", err)
+	}
+	return &created, nil
+}
+
+// GetSchedules retrieves schedules by status (all when empty), optionally for one product
+func (s *PriceScheduleService) GetSchedules(ctx context.Context, status repo.ScheduleStatus, productId int) ([]*repo.PriceSchedule, error) {
+	var schedules []*repo.PriceSchedule
+	query := `
+		SELECT *
+		FROM price_schedules
+		WHERE ($1 = '' OR status = $1)
+		AND ($2 = 0 OR product_id = $2)
+		ORDER BY starts_at DESC
+	`
+	if err := s.db.SelectContext(ctx, &schedules, query, status, productId); err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// GetScheduleByID retrieves a schedule by its ID
+func (s *PriceScheduleService) GetScheduleByID(ctx context.Context, id int) (*repo.PriceSchedule, error) {
+	var schedule repo.PriceSchedule
+	err := s.db.GetContext(ctx, &schedule, `SELECT * FROM price_schedules WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, pricing.ErrScheduleNotFound
+		}
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// CancelSchedule cancels a pending schedule or ends an active temporary one early
+func (s *PriceScheduleService) CancelSchedule(ctx context.Context, id int) (*repo.PriceSchedule, error) {
+	return s.schedules.Cancel(ctx, id)
+}
+
+// RunDue applies the schedules that are due to start and reverts the temporary ones that are
+// due to end, returning how many of each
+func (s *PriceScheduleService) RunDue(ctx context.Context) (applied int, reverted int, err error) {
+	return s.schedules.RunDue(ctx)
+}
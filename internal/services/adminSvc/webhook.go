@@ -0,0 +1,160 @@
+package adminSvc
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/Daniel-Njaramba-1/pulse/internal/webhooks"
+	"github.com/jmoiron/sqlx"
+)
+
+// WebhookService manages the webhooks partners subscribe to live events with, and their
+// delivery log
+type WebhookService struct {
+	db *sqlx.DB
+}
+
+func NewWebhookService(db *sqlx.DB) *WebhookService {
+	return &WebhookService{db: db}
+}
+
+// validateWebhook checks a webhook's URL and event types
+func validateWebhook(webhook *repo.Webhook) error {
+	u, err := url.ParseRequestURI(webhook.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return webhooks.ValidateEventTypes(webhook.EventTypes)
+}
+
+// newSecret generates a random signing secret
+func newSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// CreateWebhook registers a webhook, generating its secret unless one is given. The created
+// webhook is the only time the secret is returned.
+func (s *WebhookService) CreateWebhook(ctx context.Context, webhook *repo.Webhook) (*repo.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+	if webhook.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate secret: %w", err)
+		}
+		webhook.Secret = secret
+	}
+
+	query := `
+		INSERT INTO webhooks (url, event_types, secret, description, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING *
+	`
+	var created repo.Webhook
+	err := s.db.GetContext(ctx, &created, query, webhook.Url, webhook.EventTypes, webhook.Secret,
+		webhook.Description, webhook.IsActive, webhook.CreatedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return &created, nil
+}
+
+// GetWebhooks retrieves every webhook, without secrets
+func (s *WebhookService) GetWebhooks(ctx context.Context) ([]*repo.Webhook, error) {
+	var hooks []*repo.Webhook
+	if err := s.db.SelectContext(ctx, &hooks, `SELECT * FROM webhooks ORDER BY id`); err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		hook.Secret = ""
+	}
+	return hooks, nil
+}
+
+// GetWebhookByID retrieves a webhook by its ID, without its secret
+func (s *WebhookService) GetWebhookByID(ctx context.Context, id int) (*repo.Webhook, error) {
+	var webhook repo.Webhook
+	err := s.db.GetContext(ctx, &webhook, `SELECT * FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhooks.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	webhook.Secret = ""
+	return &webhook, nil
+}
+
+// UpdateWebhook changes a webhook's URL, event types, description and whether it is active.
+// A secret given replaces the old one; without one the old one is kept.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, webhook *repo.Webhook) (*repo.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, err
+	}
+
+	query := `
+		UPDATE webhooks
+		SET url = $2, event_types = $3, secret = COALESCE(NULLIF($4, ''), secret), description = $5, is_active = $6
+		WHERE id = $1
+		RETURNING *
+	`
+	var updated repo.Webhook
+	err := s.db.GetContext(ctx, &updated, query, webhook.Id, webhook.Url, webhook.EventTypes, webhook.Secret,
+		webhook.Description, webhook.IsActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhooks.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	updated.Secret = ""
+	return &updated, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return webhooks.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// GetDeliveries retrieves a webhook's deliveries by status (all when empty), newest first
+func (s *WebhookService) GetDeliveries(ctx context.Context, webhookId int, status repo.DeliveryStatus, limit int) ([]repo.WebhookDelivery, error) {
+	if _, err := s.GetWebhookByID(ctx, webhookId); err != nil {
+		return nil, err
+	}
+
+	var deliveries []repo.WebhookDelivery
+	query := `
+		SELECT *
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3
+	`
+	if err := s.db.SelectContext(ctx, &deliveries, query, webhookId, status, limit); err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// Redeliver sends a finished delivery again
+func (s *WebhookService) Redeliver(ctx context.Context, id int) (*repo.WebhookDelivery, error) {
+	return webhooks.Redeliver(ctx, s.db, id)
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
 	"github.com/jmoiron/sqlx"
@@ -12,10 +13,11 @@ import (
 
 type CartService struct {
 	db *sqlx.DB
+	experiments *pricing.Experiments
 }
 
-func NewCartService(db *sqlx.DB) *CartService {
-	return &CartService{db: db}
+func NewCartService(db *sqlx.DB, experiments *pricing.Experiments) *CartService {
+	return &CartService{db: db, experiments: experiments}
 }
 
 // GetCartWithItems retrieves an active cart with all unprocessed items
@@ -63,6 +65,25 @@ func (s *CartService) GetCartWithItems(ctx context.Context, userID int) (*repo.C
 	if err != nil {
 		return nil, err
 	}
+
+	// Show customers in a price experiment the price of their arm
+	productIds := make([]int, len(items))
+	for i, item := range items {
+		productIds[i] = item.ProductId
+	}
+	variants, err := s.experiments.Resolve(ctx, tx, userID, productIds)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if variant, ok := variants[item.ProductId]; ok {
+			price := float32(variant.Price)
+			items[i].ProductAdjustedPrice = &price
+		}
+	}
+	if err = s.experiments.Expose(ctx, tx, userID, variants); err != nil {
+		return nil, err
+	}
 	
 	if err = tx.Commit(); err != nil {
 		return nil, err
@@ -7,17 +7,26 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/Daniel-Njaramba-1/pulse/internal/util/logging"
 	"github.com/jmoiron/sqlx"
 )
 
+var ErrOrderNotFound = errors.New("order not found")
+
+// StaleOrderGrace is how long past its price validity a pending order is left before the
+// sweeper expires it
+const StaleOrderGrace = 2 * time.Hour
+
 type OrderService struct {
 	db *sqlx.DB
+	experiments *pricing.Experiments
 }
 
-func NewOrderService(db *sqlx.DB) *OrderService {
-	return &OrderService{ db: db}
+func NewOrderService(db *sqlx.DB, experiments *pricing.Experiments) *OrderService {
+	return &OrderService{ db: db, experiments: experiments}
 }
 
 func (s *OrderService) GenerateOrder(ctx context.Context, userId int) error {
@@ -78,6 +87,17 @@ func (s *OrderService) GenerateOrder(ctx context.Context, userId int) error {
 		return errors.New("no items in cart")
 	}
 
+	// resolve prices for products under a price experiment
+	productIds := make([]int, len(cartItems))
+	for i, item := range cartItems {
+		productIds[i] = item.ProductId
+	}
+	variants, err := s.experiments.Resolve(ctx, tx, userId, productIds)
+	if err != nil {
+		logging.LogError(fmt.Sprintf("Failed to resolve experiment prices for user %d: %v", userId, err))
+		return err
+	}
+
 	// calc price
 	var totalPrice float64
 	var orderItems []repo.OrderItem
@@ -98,14 +118,26 @@ func (s *OrderService) GenerateOrder(ctx context.Context, userId int) error {
 			return err 
 		}
 
-		itemTotal := currentPrice * float64(item.Quantity)
-		totalPrice += itemTotal
-
-		orderItems = append(orderItems, repo.OrderItem{
+		orderItem := repo.OrderItem{
 			ProductId: item.ProductId,
 			Price: currentPrice,
 			Quantity: item.Quantity,
-		})
+		}
+		if variant, ok := variants[item.ProductId]; ok {
+			arm := string(variant.Arm)
+			orderItem.Price = variant.Price
+			orderItem.ExperimentId = &variant.ExperimentId
+			orderItem.ExperimentArm = &arm
+		}
+
+		itemTotal := orderItem.Price * float64(item.Quantity)
+		totalPrice += itemTotal
+
+		orderItems = append(orderItems, orderItem)
+	}
+	if err = s.experiments.Expose(ctx, tx, userId, variants); err != nil {
+		logging.LogError(fmt.Sprintf("Failed to record experiment exposure for user %d: %v", userId, err))
+		return err
 	}
 
 	// Create the order with expiration time for price validity
@@ -136,11 +168,15 @@ func (s *OrderService) GenerateOrder(ctx context.Context, userId int) error {
 		logging.LogError(fmt.Sprintf("Failed to create order for user %d: %v", userId, err))
 		return fmt.Errorf("failed to create order: %w", err)
 	}
+	if err = orders.Placed(ctx, tx, order, orders.Customer(userId)); err != nil {
+		logging.LogError(fmt.Sprintf("Failed to record order %d for user %d: %v", order.Id, userId, err))
+		return err
+	}
 	
 	// Insert order items
 	insertOrderItemQuery := `
-		INSERT INTO order_items (order_id, product_id, price, quantity)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO order_items (order_id, product_id, price, quantity, experiment_id, experiment_arm)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 	
 	for _, item := range orderItems {
@@ -151,6 +187,8 @@ func (s *OrderService) GenerateOrder(ctx context.Context, userId int) error {
 			item.ProductId,
 			item.Price,
 			item.Quantity,
+			item.ExperimentId,
+			item.ExperimentArm,
 		)
 		
 		if err != nil {
@@ -223,3 +261,153 @@ func (s *OrderService) GetOrderWithItems (ctx context.Context, userId int) (*rep
 
 	return orderWithItems, nil
 }
+
+// GetOrders retrieves a page of the customer's orders matching the filter, newest first
+func (s *OrderService) GetOrders(ctx context.Context, userId int, filter repo.OrderFilter) (*repo.OrderPage, error) {
+	page := &repo.OrderPage{Orders: []repo.OrderSummary{}, Page: filter.Page, PageSize: filter.PageSize}
+
+	where := `
+		WHERE o.customer_id = $1 AND ($2 = '' OR o.status = $2)
+		AND ($3::timestamp IS NULL OR o.created_at >= $3)
+		AND ($4::timestamp IS NULL OR o.created_at < $4)
+	`
+	err := s.db.GetContext(ctx, &page.Total, `SELECT COUNT(*) FROM orders o`+where, userId, filter.Status, filter.From, filter.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+
+	query := `
+		SELECT o.id, o.customer_id, o.total_price, o.status, o.price_valid_until, o.created_at, o.updated_at,
+			(SELECT COUNT(*) FROM order_items oi WHERE oi.order_id = o.id) AS item_count
+		FROM orders o
+	` + where + `
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT $5 OFFSET $6
+	`
+	err = s.db.SelectContext(ctx, &page.Orders, query, userId, filter.Status, filter.From, filter.To,
+		filter.PageSize, (filter.Page-1)*filter.PageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	return page, nil
+}
+
+// GetOrder retrieves one of the customer's orders with its items, the price each sold at,
+// its payments and its status history. The history leaves out who made each change and why,
+// which can name admins and carry their notes.
+func (s *OrderService) GetOrder(ctx context.Context, userId int, orderId int) (*repo.OrderWithItems, error) {
+	order, err := orders.Detail(ctx, s.db, orderId)
+	if errors.Is(err, orders.ErrOrderNotFound) || (err == nil && order.CustomerId != userId) {
+		return nil, ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range order.History {
+		order.History[i].Actor = nil
+		order.History[i].Reason = nil
+	}
+	return order, nil
+}
+
+// CancelOrder cancels one of the customer's pending orders and puts its items back in their
+// cart, so that they can check out again. Orders past pending cannot be cancelled.
+func (s *OrderService) CancelOrder(ctx context.Context, userId int, orderId int) (*repo.Order, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var order repo.Order
+	err = tx.GetContext(ctx, &order, `SELECT * FROM orders WHERE id = $1 AND customer_id = $2 FOR UPDATE`, orderId, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	cancelled, err := closeOrder(ctx, tx, &order, repo.OrderStatusCancelled, orders.Customer(userId), "cancelled by customer")
+	if err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return cancelled, nil
+}
+
+// ExpireStaleOrders fails the pending orders whose prices ran out more than grace ago and
+// puts their items back in their customers' carts. It returns how many were expired.
+func (s *OrderService) ExpireStaleOrders(ctx context.Context, grace time.Duration) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Orders being paid for right now are locked, and skipped until the next sweep
+	var stale []repo.Order
+	query := `
+		SELECT *
+		FROM orders
+		WHERE status = 'pending' AND price_valid_until < $1
+		ORDER BY id
+		FOR UPDATE SKIP LOCKED
+	`
+	if err = tx.SelectContext(ctx, &stale, query, time.Now().Add(-grace)); err != nil {
+		return 0, fmt.Errorf("failed to get stale orders: %w", err)
+	}
+
+	for i := range stale {
+		if _, err = closeOrder(ctx, tx, &stale[i], repo.OrderStatusFailed, orders.System, "expired unpaid"); err != nil {
+			return 0, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(stale), nil
+}
+
+// closeOrder ends a pending order with status and restores its items to the customer's cart,
+// adding to any of the same products put in the cart since
+func closeOrder(ctx context.Context, tx *sqlx.Tx, order *repo.Order, status repo.OrderStatus, actor orders.Actor, reason string) (*repo.Order, error) {
+	closed, err := orders.Transition(ctx, tx, order.Id, status, actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	var cartId int
+	err = tx.GetContext(ctx, &cartId, `SELECT id FROM carts WHERE customer_id = $1 AND is_active = TRUE LIMIT 1`, order.CustomerId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return closed, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart for user %d: %w", order.CustomerId, err)
+	}
+
+	mergeQuery := `
+		UPDATE cart_items ci
+		SET quantity = ci.quantity + oi.quantity
+		FROM order_items oi
+		WHERE oi.order_id = $1 AND ci.cart_id = $2 AND ci.product_id = oi.product_id AND ci.is_processed = FALSE
+	`
+	if _, err = tx.ExecContext(ctx, mergeQuery, order.Id, cartId); err != nil {
+		return nil, fmt.Errorf("failed to restore cart items for order %d: %w", order.Id, err)
+	}
+	insertQuery := `
+		INSERT INTO cart_items (cart_id, product_id, quantity, is_processed)
+		SELECT $2, oi.product_id, oi.quantity, FALSE
+		FROM order_items oi
+		WHERE oi.order_id = $1 AND NOT EXISTS (
+			SELECT 1 FROM cart_items ci
+			WHERE ci.cart_id = $2 AND ci.product_id = oi.product_id AND ci.is_processed = FALSE
+		)
+	`
+	if _, err = tx.ExecContext(ctx, insertQuery, order.Id, cartId); err != nil {
+		return nil, fmt.Errorf("failed to restore cart items for order %d: %w", order.Id, err)
+	}
+	return closed, nil
+}
@@ -5,16 +5,20 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/jmoiron/sqlx"
 )
 
 type PaymentService struct {
 	db *sqlx.DB
+	experiments *pricing.Experiments
 }
 
-func NewPaymentService (db *sqlx.DB) *PaymentService {
-	return &PaymentService{db: db}
+func NewPaymentService (db *sqlx.DB, experiments *pricing.Experiments) *PaymentService {
+	return &PaymentService{db: db, experiments: experiments}
 }
 
 func (s *PaymentService) ProcessPayment(ctx context.Context, userId int) (string, error) {
@@ -55,6 +59,16 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, userId int) (string
 	// If price is no longer valid, recalculate prices
 	if !isPriceValid {
 		var newTotalPrice float64 = 0
+
+		// Customers in a price experiment pay the price of their arm
+		productIds := make([]int, len(orderItems))
+		for i, item := range orderItems {
+			productIds[i] = item.ProductId
+		}
+		variants, err := s.experiments.Resolve(ctx, tx, userId, productIds)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve experiment prices: %w", err)
+		}
 		
 		for i, item := range orderItems {
 			// Get current price for each product
@@ -68,20 +82,31 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, userId int) (string
 			if err != nil {
 				return "", fmt.Errorf("failed to get current price for product %d: %w", item.ProductId, err)
 			}
+
+			var experimentId *int
+			var experimentArm *string
+			if variant, ok := variants[item.ProductId]; ok {
+				arm := string(variant.Arm)
+				currentPrice = variant.Price
+				experimentId = &variant.ExperimentId
+				experimentArm = &arm
+			}
 			
 			// Update item price
 			updateItemQuery := `
 				UPDATE order_items
-				SET price = $1
-				WHERE id = $2
+				SET price = $1, experiment_id = $2, experiment_arm = $3
+				WHERE id = $4
 			`
-			_, err = tx.ExecContext(ctx, updateItemQuery, currentPrice, item.Id)
+			_, err = tx.ExecContext(ctx, updateItemQuery, currentPrice, experimentId, experimentArm, item.Id)
 			if err != nil {
 				return "", fmt.Errorf("failed to update item price: %w", err)
 			}
 			
 			// Update local item price for sales record later
 			orderItems[i].Price = currentPrice
+			orderItems[i].ExperimentId = experimentId
+			orderItems[i].ExperimentArm = experimentArm
 			
 			// Add to new total
 			newTotalPrice += currentPrice * float64(item.Quantity)
@@ -144,26 +169,37 @@ func (s *PaymentService) ProcessPayment(ctx context.Context, userId int) (string
 		return "", fmt.Errorf("failed to create payment record: %w", err)
 	}
 
-	// Update order status
-	_, err = tx.ExecContext(ctx, `
-		UPDATE orders 
-		SET status = $2 
-		WHERE id = $1
-	`, order.Id, repo.OrderStatusCompleted)
+	// Mark the order paid
+	_, err = orders.Transition(ctx, tx, order.Id, repo.OrderStatusPaid, orders.Customer(userId), "payment "+paymentId)
 	if err != nil {
-		return "", fmt.Errorf("failed to update order status: %w", err)
+		return "", err
 	}
 
 	// Generate sales records and update stock for each item
 	for _, item := range orderItems {
 		// Create sales record
-		_, err = tx.ExecContext(ctx, `
+		var saleId int
+		err = tx.QueryRowxContext(ctx, `
 			INSERT INTO sales (order_item_id, product_id, sale_price, quantity)
 			VALUES ($1, $2, $3, $4)
-		`, item.Id, item.ProductId, item.Price, item.Quantity)
+			RETURNING id
+		`, item.Id, item.ProductId, item.Price, item.Quantity).Scan(&saleId)
 		if err != nil {
 			return "", fmt.Errorf("failed to create sales record: %w", err)
 		}
+
+		// Announce the sale through the outbox so it reprices the product even if
+		// nothing is listening right now
+		err = outbox.Enqueue(ctx, tx, outbox.TopicSale, outbox.SaleEvent{
+			SaleId:      saleId,
+			OrderItemId: item.Id,
+			ProductId:   item.ProductId,
+			SalePrice:   item.Price,
+			Quantity:    item.Quantity,
+		})
+		if err != nil {
+			return "", err
+		}
 		
 		// Update product metrics
 		_, err = tx.ExecContext(ctx, `
@@ -0,0 +1,226 @@
+package customerSvc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/lib/pq"
+)
+
+var ErrProductNotFound = errors.New("product not found")
+
+// Price history intervals
+const (
+	PriceIntervalDay  = "day"
+	PriceIntervalWeek = "week"
+)
+
+const (
+	// MaxPriceHistoryDays caps how far back a price history reaches
+	MaxPriceHistoryDays = 365
+	// priceRangeDays is the window of the low and high prices
+	priceRangeDays = 30
+	// priceDropDays is how far back a price must have been higher to count as a recent drop
+	priceDropDays = 7
+	// priceTrendDays is the length of the sparkline attached to product listings
+	priceTrendDays = 14
+)
+
+// dailyPrice is a product's price over one day
+type dailyPrice struct {
+	date  time.Time
+	close float64
+	low   float64
+	high  float64
+}
+
+// effectivePriceMoves selects the logged changes to the price customers pay for products
+// $1: every adjusted price change, and the base price changes made while no adjusted price was
+// in effect. Adjusted prices are never cleared, so a base change counts when no adjusted change
+// came before it, unless the product has an adjusted price that was never logged.
+const effectivePriceMoves = `
+	SELECT pa.id, pa.product_id, pa.old_price, pa.new_price, pa.created_at
+	FROM price_adjustments pa
+	JOIN product_metrics pm ON pm.product_id = pa.product_id
+	WHERE pa.product_id = ANY($1) AND (
+		pa.price_type = 'adjusted'
+		OR (
+			NOT EXISTS (
+				SELECT 1 FROM price_adjustments a
+				WHERE a.product_id = pa.product_id AND a.price_type = 'adjusted' AND a.created_at <= pa.created_at
+			)
+			AND (
+				pm.adjusted_price IS NULL
+				OR EXISTS (SELECT 1 FROM price_adjustments a WHERE a.product_id = pa.product_id AND a.price_type = 'adjusted')
+			)
+		)
+	)
+`
+
+// dailyPrices rebuilds each product's daily prices over the last days days, today included,
+// from the price customers pay: the adjusted price, or the base price while there is none.
+// A day opens at the previous day's close, and every effective price change logged in
+// price_adjustments that day moves it. The whole set is read in two queries.
+func (s *ProductService) dailyPrices(ctx context.Context, productIds []int, days int) (map[int][]dailyPrice, error) {
+	var openings []struct {
+		ProductId int       `db:"product_id"`
+		Price     float64   `db:"price"`
+		Start     time.Time `db:"start"`
+	}
+	openingQuery := `
+		WITH moves AS (` + effectivePriceMoves + `)
+		SELECT
+			p.id AS product_id,
+			COALESCE(
+				(
+					SELECT m.new_price FROM moves m
+					WHERE m.product_id = p.id AND m.created_at < w.start
+					ORDER BY m.created_at DESC, m.id DESC
+					LIMIT 1
+				),
+				(
+					SELECT m.old_price FROM moves m
+					WHERE m.product_id = p.id AND m.created_at >= w.start
+					ORDER BY m.created_at, m.id
+					LIMIT 1
+				),
+				pm.adjusted_price, pm.base_price
+			) AS price,
+			w.start
+		FROM products p
+		JOIN product_metrics pm ON pm.product_id = p.id
+		CROSS JOIN (SELECT (CURRENT_DATE - ($2::int - 1))::timestamp AS start) w
+		WHERE p.id = ANY($1)
+	`
+	if err := s.db.SelectContext(ctx, &openings, openingQuery, pq.Array(productIds), days); err != nil {
+		return nil, fmt.Errorf("failed to get opening prices: %w", err)
+	}
+
+	var moves []struct {
+		ProductId int       `db:"product_id"`
+		NewPrice  float64   `db:"new_price"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	movesQuery := `
+		WITH moves AS (` + effectivePriceMoves + `)
+		SELECT product_id, new_price, created_at
+		FROM moves
+		WHERE created_at >= CURRENT_DATE - ($2::int - 1)
+		ORDER BY product_id, created_at, id
+	`
+	if err := s.db.SelectContext(ctx, &moves, movesQuery, pq.Array(productIds), days); err != nil {
+		return nil, fmt.Errorf("failed to get price adjustments: %w", err)
+	}
+	movesByProduct := map[int][]float64{}
+	moveDays := map[int][]time.Time{}
+	for _, move := range moves {
+		movesByProduct[move.ProductId] = append(movesByProduct[move.ProductId], move.NewPrice)
+		moveDays[move.ProductId] = append(moveDays[move.ProductId], truncateDay(move.CreatedAt))
+	}
+
+	series := make(map[int][]dailyPrice, len(openings))
+	for _, opening := range openings {
+		prices, dates := movesByProduct[opening.ProductId], moveDays[opening.ProductId]
+		start := truncateDay(opening.Start)
+		price := opening.Price
+		next := 0
+		daily := make([]dailyPrice, days)
+		for i := range daily {
+			day := start.AddDate(0, 0, i)
+			daily[i] = dailyPrice{date: day, close: price, low: price, high: price}
+			for ; next < len(prices) && !dates[next].After(day); next++ {
+				price = prices[next]
+				daily[i].close = price
+				daily[i].low = min(daily[i].low, price)
+				daily[i].high = max(daily[i].high, price)
+			}
+		}
+		series[opening.ProductId] = daily
+	}
+	return series, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// GetPriceHistory retrieves a product's price over the last days days, bucketed by day or week,
+// with its 30-day low and high and whether it is lower than a week ago
+func (s *ProductService) GetPriceHistory(ctx context.Context, productId int, interval string, days int) (*repo.PriceHistory, error) {
+	switch interval {
+	case PriceIntervalDay, PriceIntervalWeek:
+	default:
+		return nil, fmt.Errorf("invalid interval: %q", interval)
+	}
+	if days <= 0 || days > MaxPriceHistoryDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", MaxPriceHistoryDays)
+	}
+
+	series, err := s.dailyPrices(ctx, []int{productId}, max(days, priceRangeDays))
+	if err != nil {
+		return nil, err
+	}
+	daily, ok := series[productId]
+	if !ok {
+		return nil, ErrProductNotFound
+	}
+
+	current := daily[len(daily)-1].close
+	history := &repo.PriceHistory{
+		ProductId:       productId,
+		Interval:        interval,
+		CurrentPrice:    current,
+		Low30d:          current,
+		High30d:         current,
+		DroppedRecently: daily[len(daily)-1-priceDropDays].close > current,
+	}
+	for _, day := range daily[len(daily)-priceRangeDays:] {
+		history.Low30d = min(history.Low30d, day.low)
+		history.High30d = max(history.High30d, day.high)
+	}
+
+	daily = daily[len(daily)-days:]
+	history.Points = make([]repo.PriceHistoryPoint, 0, len(daily))
+	for _, day := range daily {
+		if interval == PriceIntervalWeek && len(history.Points) > 0 && day.date.Weekday() != time.Monday {
+			point := &history.Points[len(history.Points)-1]
+			point.Price = day.close
+			point.Low = min(point.Low, day.low)
+			point.High = max(point.High, day.high)
+			continue
+		}
+		history.Points = append(history.Points, repo.PriceHistoryPoint{
+			Date:  day.date,
+			Price: day.close,
+			Low:   day.low,
+			High:  day.high,
+		})
+	}
+	return history, nil
+}
+
+// attachPriceTrends sets each product's sparkline of daily closing prices
+func (s *ProductService) attachPriceTrends(ctx context.Context, products []*repo.ProductDetail) error {
+	productIds := make([]int, len(products))
+	for i, product := range products {
+		productIds[i] = product.Id
+	}
+	series, err := s.dailyPrices(ctx, productIds, priceTrendDays)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		daily := series[product.Id]
+		if len(daily) == 0 {
+			continue
+		}
+		product.PriceTrend = make([]float64, len(daily))
+		for i, day := range daily {
+			product.PriceTrend[i] = day.close
+		}
+	}
+	return nil
+}
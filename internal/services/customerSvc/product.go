@@ -3,16 +3,18 @@ package customerSvc
 import (
 	"context"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/pricing"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/jmoiron/sqlx"
 )
 
 type ProductService struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	engine *pricing.Engine
 }
 
-func NewProductService(db *sqlx.DB) *ProductService {
-	return &ProductService{db: db}
+func NewProductService(db *sqlx.DB, engine *pricing.Engine) *ProductService {
+	return &ProductService{db: db, engine: engine}
 }
 
 // GetProductByID retrieves a product by its ID
@@ -65,7 +67,7 @@ func (s *ProductService) GetProductByName(ctx context.Context, productName strin
     return &productDetail, nil
 }
 
-// GetAllProducts retrieves all products
+// GetAllProducts retrieves all products, each with a sparkline of its recent prices
 func (s *ProductService) GetAllProducts(ctx context.Context) ([]*repo.ProductDetail, error) {
 	var productDetail []*repo.ProductDetail
     query := `
@@ -86,5 +88,13 @@ func (s *ProductService) GetAllProducts(ctx context.Context) ([]*repo.ProductDet
     if err != nil {
         return nil, err
     }
+    if err = s.attachPriceTrends(ctx, productDetail); err != nil {
+        return nil, err
+    }
     return productDetail, nil
-}
\ No newline at end of file
+}
+
+// ExplainPrice breaks a product's current price down into the pricing model's terms
+func (s *ProductService) ExplainPrice(ctx context.Context, productId int) (*pricing.PriceExplanation, error) {
+	return s.engine.Explain(ctx, productId)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/Daniel-Njaramba-1/pulse/internal/orders"
 	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type ReviewService struct {
@@ -39,11 +41,11 @@ func (s *ReviewService) VerifyPurchase(ctx context.Context, userId int, productI
 		FROM sales
 		WHERE product_id = $1 AND order_item_id IN (
 			SELECT id FROM order_items WHERE order_id IN (
-				SELECT id FROM orders WHERE customer_id = $2 AND status = 'completed'
+				SELECT id FROM orders WHERE customer_id = $2 AND status = ANY($3)
 			)
 		)
 	`
-	err := s.db.QueryRowContext(ctx, query, productId, userId).Scan(&count)
+	err := s.db.QueryRowContext(ctx, query, productId, userId, pq.Array(orders.Purchased)).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -65,11 +67,11 @@ func (s *ReviewService) ReviewProduct(ctx context.Context, review *repo.Review)
 		FROM sales
 		WHERE product_id = $1 AND order_item_id IN (
 			SELECT id FROM order_items WHERE order_id IN (
-				SELECT id FROM orders WHERE customer_id = $2 AND status = 'completed'
+				SELECT id FROM orders WHERE customer_id = $2 AND status = ANY($3)
 			)
 		)
 	`
-	err = tx.QueryRowContext(ctx, query, review.ProductId, review.CustomerId).Scan(&count)
+	err = tx.QueryRowContext(ctx, query, review.ProductId, review.CustomerId, pq.Array(orders.Purchased)).Scan(&count)
 	if err != nil {
 		return err
 	}
@@ -5,11 +5,11 @@ import (
     "os"
 )
 
-// Global logging instance
+// Global logging instance. Messages go to stderr until InitLogging opens the log file.
 var (
     logFile  *os.File
-    logInfo  *log.Logger
-    logError *log.Logger
+    logInfo  = log.New(os.Stderr, "[INFO] ", log.Ldate|log.Ltime|log.Lshortfile)
+    logError = log.New(os.Stderr, "[ERROR] ", log.Ldate|log.Ltime|log.Lshortfile)
 )
 
 // Initialize logging
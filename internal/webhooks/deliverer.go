@@ -0,0 +1,181 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/outbox"
+	"github.com/Daniel-Njaramba-1/pulse/internal/repo"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// DefaultTimeout is how long a webhook has to respond
+	DefaultTimeout = 10 * time.Second
+	// maxResponseBody is how much of a response is kept in the delivery log
+	maxResponseBody = 1024
+)
+
+// DeliveryJob is the outbox payload that sends one delivery. The database queues one with
+// every delivery it records, in the transaction of the change the event describes.
+type DeliveryJob struct {
+	DeliveryId int `json:"delivery_id"`
+}
+
+// Deliverer sends deliveries to their webhooks. It is the outbox handler for webhook jobs, so
+// a failed attempt is retried with the dispatcher's backoff; MaxAttempts should match the
+// dispatcher's so that the delivery is marked failed when the job gives up.
+type Deliverer struct {
+	db          *sqlx.DB
+	client      *http.Client
+	MaxAttempts int
+}
+
+func NewDeliverer(db *sqlx.DB) *Deliverer {
+	return &Deliverer{
+		db:          db,
+		client:      &http.Client{Timeout: DefaultTimeout},
+		MaxAttempts: outbox.DefaultMaxAttempts,
+	}
+}
+
+// Deliver handles a webhook job: it posts the delivery's payload, signed, and records the attempt
+func (d *Deliverer) Deliver(ctx context.Context, data json.RawMessage) error {
+	var job DeliveryJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("invalid delivery job: %w", err)
+	}
+
+	var target struct {
+		repo.WebhookDelivery
+		Url      string `db:"url"`
+		Secret   string `db:"secret"`
+		IsActive bool   `db:"is_active"`
+	}
+	query := `
+		SELECT d.*, w.url, w.secret, w.is_active
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.id = $1
+	`
+	err := d.db.GetContext(ctx, &target, query, job.DeliveryId)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The webhook was deleted along with its deliveries
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get delivery %d: %w", job.DeliveryId, err)
+	}
+	if target.Status != repo.DeliveryStatusPending {
+		return nil
+	}
+	if !target.IsActive {
+		return d.record(ctx, target.Id, repo.DeliveryStatusFailed, nil, nil, errors.New("webhook is disabled"))
+	}
+
+	responseStatus, responseBody, sendErr := d.send(ctx, target.Url, target.Secret, &target.WebhookDelivery)
+	status := repo.DeliveryStatusPending
+	switch {
+	case sendErr == nil:
+		status = repo.DeliveryStatusDelivered
+	case target.Attempts+1 >= d.MaxAttempts:
+		status = repo.DeliveryStatusFailed
+	}
+	if err := d.record(ctx, target.Id, status, responseStatus, responseBody, sendErr); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// send posts a delivery and returns the response; any status but 2xx is an error
+func (d *Deliverer) send(ctx context.Context, url, secret string, delivery *repo.WebhookDelivery) (*int, *string, error) {
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(delivery.Id))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	responseBody := string(body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &resp.StatusCode, &responseBody, fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return &resp.StatusCode, &responseBody, nil
+}
+
+// record logs an attempt at a delivery
+func (d *Deliverer) record(ctx context.Context, id int, status repo.DeliveryStatus, responseStatus *int, responseBody *string, sendErr error) error {
+	var lastError *string
+	if sendErr != nil {
+		message := sendErr.Error()
+		lastError = &message
+	}
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, response_status = $3, response_body = $4, last_error = $5,
+			delivered_at = CASE WHEN $2 = 'delivered' THEN NOW() ELSE delivered_at END
+		WHERE id = $1
+	`
+	if _, err := d.db.ExecContext(ctx, query, id, status, responseStatus, responseBody, lastError); err != nil {
+		return fmt.Errorf("failed to record delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// Redeliver sends a delivery again with a fresh set of attempts, whether it was delivered or
+// failed. The payload is the one originally sent.
+func Redeliver(ctx context.Context, db *sqlx.DB, id int) (*repo.WebhookDelivery, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var delivery repo.WebhookDelivery
+	err = tx.GetContext(ctx, &delivery, `SELECT * FROM webhook_deliveries WHERE id = $1 FOR UPDATE`, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery %d: %w", id, err)
+	}
+	if delivery.Status == repo.DeliveryStatusPending {
+		return nil, ErrDeliveryPending
+	}
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, last_error = NULL
+		WHERE id = $1
+		RETURNING *
+	`
+	if err = tx.GetContext(ctx, &delivery, query, id); err != nil {
+		return nil, fmt.Errorf("failed to update delivery %d: %w", id, err)
+	}
+	if err = outbox.Enqueue(ctx, tx, outbox.TopicWebhook, DeliveryJob{DeliveryId: id}); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
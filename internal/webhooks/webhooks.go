@@ -0,0 +1,68 @@
+// Package webhooks sends live events to the endpoints subscribed to them. Every event a
+// webhook wants becomes a delivery, recorded by database triggers in the same transaction as
+// the change, sent through the outbox so that it is retried with backoff, and signed so that
+// the receiver can check it came from us.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Daniel-Njaramba-1/pulse/internal/events"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrDeliveryPending  = errors.New("delivery is still being attempted")
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Pulse-Event"
+	HeaderDelivery  = "X-Pulse-Delivery"
+	HeaderTimestamp = "X-Pulse-Timestamp"
+	HeaderSignature = "X-Pulse-Signature"
+)
+
+// EventTypes are the events a webhook can subscribe to, the ones the delivery triggers record.
+// Order events carry the order's status, so a paid order is an order_status event with status paid.
+var EventTypes = []events.Topic{
+	events.TopicPriceChange,
+	events.TopicStockChange,
+	events.TopicRestock,
+	events.TopicLowStock,
+	events.TopicOrderStatus,
+}
+
+// ValidateEventTypes checks that a webhook subscribes to at least one known event type
+func ValidateEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return errors.New("event_types is required")
+	}
+	for _, name := range eventTypes {
+		known := false
+		for _, eventType := range EventTypes {
+			known = known || name == string(eventType)
+		}
+		if !known {
+			return fmt.Errorf("unknown event type: %q", name)
+		}
+	}
+	return nil
+}
+
+// Sign returns the signature of a delivery: the hex HMAC-SHA256, keyed with the webhook's
+// secret, of the timestamp and body joined by a dot. Receivers recompute it to check the
+// header, and reject old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}